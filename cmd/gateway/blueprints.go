@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/client"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+var blueprintsCmd = &cobra.Command{
+	Use:   "blueprints",
+	Short: "Manages blueprint files and the blueprint registry",
+}
+
+var pushCmd = &cobra.Command{
+	Use:   "push <dir>",
+	Short: "Uploads a directory of blueprints through the batch endpoint",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, _ := cmd.Flags().GetString("version")
+
+		entries, err := readBlueprintDir(args[0])
+		if err != nil {
+			return err
+		}
+
+		batch, err := model.BatchFromEntries(version, entries)
+		if err != nil {
+			return err
+		}
+
+		batch.Force, _ = cmd.Flags().GetBool("force")
+
+		c, err := registryClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if err := c.PushBatch(cmd.Context(), batch); err != nil {
+			return fmt.Errorf("push: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "pushed %d blueprints to version %s\n", batch.Len(), batch.Version)
+
+		return nil
+	},
+}
+
+var pullCmd = &cobra.Command{
+	Use:   "pull <version> <dir>",
+	Short: "Exports a version of the registry to files",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := registryClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		batch, err := c.Export(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("pull: %w", err)
+		}
+
+		if err := model.WriteDir(args[1], batch); err != nil {
+			return fmt.Errorf("pull: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "pulled %d blueprints of version %s\n", batch.Len(), batch.Version)
+
+		return nil
+	},
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <dir>",
+	Short: "Validates a directory of blueprints with the rules of the server",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := readBlueprintDir(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%d blueprints OK\n", len(entries))
+
+		return nil
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <dir> <version>",
+	Short: "Compares a directory of blueprints with a version of the registry",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := readBlueprintDir(args[0])
+		if err != nil {
+			return err
+		}
+
+		local, err := model.BatchFromEntries(args[1], entries)
+		if err != nil {
+			return err
+		}
+
+		c, err := registryClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		remote, err := c.Export(cmd.Context(), args[1])
+		if err != nil && !errors.Is(err, client.ErrNotFound) {
+			return fmt.Errorf("diff: %w", err)
+		}
+
+		// Templates of the directory may extend blueprints only the registry
+		// holds, as they do when pushed.
+		if err := local.Resolve(remote.Base()); err != nil {
+			return err
+		}
+
+		changes, err := model.Diff(local, remote)
+		if err != nil {
+			return err
+		}
+
+		symbols := map[string]string{
+			model.ChangeAdded:   "+",
+			model.ChangeRemoved: "-",
+			model.ChangeChanged: "~",
+		}
+
+		for _, change := range changes {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s/%s\n", symbols[change.Op], change.Kind, change.Slug)
+		}
+
+		if exitCode, _ := cmd.Flags().GetBool("exit-code"); exitCode && len(changes) > 0 {
+			return fmt.Errorf("%d blueprints differ", len(changes))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	blueprintsCmd.PersistentFlags().String(config.FlagRegistryServer, "http://127.0.0.1:8080", "gateway URL")
+	blueprintsCmd.PersistentFlags().String(config.FlagToken, "", "bearer token")
+	blueprintsCmd.PersistentFlags().String(config.FlagTokenURL, "", "token endpoint for the client credentials flow (default is discovered from the OIDC provider)")
+	blueprintsCmd.PersistentFlags().String(config.FlagClientID, "", "client ID for the client credentials flow")
+	blueprintsCmd.PersistentFlags().String(config.FlagClientSecret, "", "client secret for the client credentials flow")
+
+	pushCmd.Flags().String("version", "", "version to upload to (default is the version in the files)")
+	pushCmd.Flags().Bool("force", false, "overwrite existing blueprints")
+	diffCmd.Flags().Bool("exit-code", false, "exit with an error if there are differences")
+
+	bindFlags := map[string]string{
+		config.FlagRegistryServer: config.EnvRegistryServer,
+		config.FlagToken:          config.EnvToken,
+		config.FlagTokenURL:       config.EnvTokenURL,
+		config.FlagClientID:       config.EnvClientID,
+		config.FlagClientSecret:   config.EnvClientSecret,
+	}
+
+	for flag, env := range bindFlags {
+		if err := viper.BindPFlag(flag, blueprintsCmd.PersistentFlags().Lookup(flag)); err != nil {
+			slog.Warn("failed to bind flag", "error", err, "name", flag)
+		}
+
+		env = fmt.Sprintf("%s_%s", config.EnvPrefix, env)
+		if err := viper.BindEnv(flag, env); err != nil {
+			slog.Warn("failed to bind env", "error", err, "flag", flag, "env", env)
+		}
+	}
+
+	for _, cmd := range []*cobra.Command{pushCmd, pullCmd, lintCmd, diffCmd} {
+		// Errors of these commands are about blueprints, not about how the
+		// command was called.
+		cmd.SilenceUsage = true
+		blueprintsCmd.AddCommand(cmd)
+	}
+
+	rootCmd.AddCommand(blueprintsCmd)
+}
+
+// readBlueprintDir reads and lints a blueprint directory. All problems are
+// reported at once.
+func readBlueprintDir(dir string) ([]model.DirEntry, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	entries, readErr := model.ReadDir(dir)
+	if entries == nil {
+		return nil, readErr
+	}
+
+	if err := errors.Join(readErr, model.Lint(entries)); err != nil {
+		return nil, fmt.Errorf("invalid blueprints:\n%w", err)
+	}
+
+	return entries, nil
+}
+
+func registryClient(ctx context.Context) (*client.Client, error) {
+	server := viper.GetString(config.FlagRegistryServer)
+
+	if token := viper.GetString(config.FlagToken); token != "" {
+		return client.New(server, client.WithToken(token)), nil
+	}
+
+	clientID := viper.GetString(config.FlagClientID)
+	if clientID == "" {
+		return client.New(server), nil
+	}
+
+	tokenURL := viper.GetString(config.FlagTokenURL)
+	if tokenURL == "" {
+		issuer := viper.GetString(config.FlagOidcProvider)
+		if issuer == "" {
+			return nil, fmt.Errorf("client credentials need --%s or --%s", config.FlagTokenURL, config.FlagOidcProvider)
+		}
+
+		provider, err := oidc.NewProvider(ctx, issuer)
+		if err != nil {
+			return nil, fmt.Errorf("oidc: %w", err)
+		}
+
+		tokenURL = provider.Endpoint().TokenURL
+	}
+
+	credentials := clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: viper.GetString(config.FlagClientSecret),
+		TokenURL:     tokenURL,
+	}
+
+	return client.New(server, client.WithClientCredentials(credentials)), nil
+}
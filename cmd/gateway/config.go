@@ -2,14 +2,37 @@ package main
 
 import (
 	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/asset"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	registrydb "github.com/GnarloqGames/genesis-avalon-gateway/platform/cockroach"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
 	"github.com/GnarloqGames/genesis-avalon-kit/database/cockroach"
 	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
 	"github.com/spf13/viper"
 )
 
 func setConfigs() {
+	databaseConfig()
 	cockroachConfig()
 	registryConfig()
+	assetConfig()
+}
+
+func databaseConfig() {
+	kind := viper.GetString(config.FlagDatabaseKind)
+
+	database.SetKind(kind)
+	metadata.SetKind(kind)
+	audit.SetKind(kind)
+
+	// The kit's cockroach backend only adds blueprints, deletes and forced
+	// saves go through the gateway's pool.
+	if kind == database.DriverCockroach {
+		blueprint.SetWriter(registrydb.Registry{})
+	}
 }
 
 func cockroachConfig() {
@@ -17,8 +40,16 @@ func cockroachConfig() {
 	cockroach.SetUsername(viper.GetString(config.FlagDatabaseUsername))
 	cockroach.SetPassword(viper.GetString(config.FlagDatabasePassword))
 	cockroach.SetDatabase(viper.GetString(config.FlagDatabaseName))
+	cockroach.SetPort(viper.GetUint16(config.FlagDatabasePort))
 }
 
 func registryConfig() {
-	cache.SetVersion(viper.GetString(config.FlagBlueprintVersion))
+	version := viper.GetString(config.FlagBlueprintVersion)
+
+	cache.SetVersion(version)
+	cluster.SetActive(version)
+}
+
+func assetConfig() {
+	asset.SetKind(viper.GetString(config.FlagAssetStore))
 }
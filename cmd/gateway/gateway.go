@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -12,7 +13,14 @@ import (
 	"github.com/GnarloqGames/genesis-avalon-gateway/config"
 	"github.com/GnarloqGames/genesis-avalon-gateway/logging"
 	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth/provider/oidc"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cockroach"
 	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/lookup"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/snapshot"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/watcher"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/webhook"
 	"github.com/GnarloqGames/genesis-avalon-kit/observability"
 	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
 	"github.com/GnarloqGames/genesis-avalon-kit/transport"
@@ -58,8 +66,40 @@ var startCmd = &cobra.Command{
 			return fmt.Errorf("oidc: %w", err)
 		}
 
-		if err := cache.Load(cmdContext); err != nil {
-			return fmt.Errorf("registry: %w", err)
+		// The pool of the gateway's own tables is opened on first use.
+		defer cockroach.Close()
+
+		if err := startWebhooks(); err != nil {
+			return fmt.Errorf("webhooks: %w", err)
+		}
+		defer drainWebhooks()
+
+		cluster.OnLoad(func(ctx context.Context, version string) {
+			if err := snapshot.Save(ctx, version); err != nil {
+				slog.Warn("failed to save blueprint snapshot", "error", err, "version", version)
+			}
+		})
+
+		channel := metadata.ChannelForEnvironment(viper.GetString(config.FlagEnvironment))
+		blueprintDir := viper.GetString(config.FlagBlueprintDir)
+
+		// With a blueprint directory the cache is loaded by the first sync of
+		// the watcher, the dev version may not exist yet. No version is
+		// reported active until it has loaded.
+		if blueprintDir != "" {
+			cluster.SetActive("")
+		} else {
+			if channel != "" {
+				followChannel(cmdContext, channel)
+			}
+
+			if err := cache.Load(cmdContext); err != nil {
+				if err := bootFromSnapshot(err); err != nil {
+					return fmt.Errorf("registry: %w", err)
+				}
+			} else {
+				cluster.Loaded(cmdContext, cluster.Active())
+			}
 		}
 
 		bus, err := initMessageBus()
@@ -68,6 +108,45 @@ var startCmd = &cobra.Command{
 		}
 		defer bus.Close()
 
+		node, err := cluster.Join(bus, string(channel))
+		if err != nil {
+			return fmt.Errorf("cluster: %w", err)
+		}
+		defer func() {
+			if err := node.Leave(); err != nil {
+				slog.Error("failed to leave gateway cluster", "error", err)
+			}
+		}()
+
+		if _, stale := snapshot.Stale(); stale {
+			go snapshot.Retry(cmdContext, func(ctx context.Context) error {
+				return node.Reload(ctx, channelVersion(ctx, channel))
+			}, snapshot.DefaultRetryMin, snapshot.DefaultRetryMax)
+		}
+
+		if blueprintDir != "" {
+			w := watcher.New(blueprintDir, node.Reload)
+			if err := w.Sync(cmdContext); err != nil {
+				return fmt.Errorf("blueprint dir: %w", err)
+			}
+
+			go func() {
+				if err := w.Run(cmdContext); err != nil {
+					slog.Error("blueprint watcher stopped", "error", err)
+				}
+			}()
+		}
+
+		lookups, err := lookup.Serve(bus)
+		if err != nil {
+			return fmt.Errorf("lookup: %w", err)
+		}
+		defer func() {
+			if err := lookups.Close(); err != nil {
+				slog.Error("failed to stop blueprint lookups", "error", err)
+			}
+		}()
+
 		s := daemon.Start(bus, oidcVerifier)
 
 		<-cmdContext.Done()
@@ -107,7 +186,14 @@ func init() {
 	rootCmd.PersistentFlags().String(config.FlagDatabaseName, "", "Database name")
 	rootCmd.PersistentFlags().String(config.FlagDatabaseUsername, "", "Database username")
 	rootCmd.PersistentFlags().String(config.FlagDatabasePassword, "", "Database password")
+	rootCmd.PersistentFlags().Uint16(config.FlagDatabasePort, uint16(26257), "Database port")
+	rootCmd.PersistentFlags().String(config.FlagDatabaseSSLMode, "verify-full", "SSL mode of the gateway's own database connections")
 	rootCmd.PersistentFlags().String(config.FlagBlueprintVersion, "", "Blueprint version")
+	rootCmd.PersistentFlags().Int(config.FlagBlueprintCache, 8, "Number of promoted blueprint versions kept in memory")
+	rootCmd.PersistentFlags().String(config.FlagSnapshotDir, "/var/lib/gatewayd/snapshots", "Directory of blueprint snapshots served while the registry is unreachable")
+	rootCmd.PersistentFlags().String(config.FlagDefaultLocale, "en", "Locale of blueprint display data served when no requested locale is available")
+	rootCmd.PersistentFlags().String(config.FlagAssetStore, "filesystem", "Store of blueprint assets such as icons (filesystem or memory)")
+	rootCmd.PersistentFlags().String(config.FlagAssetDir, "/var/lib/gatewayd/assets", "Directory of blueprint assets with the filesystem store")
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is /etc/gatewayd/config.yaml)")
 
 	envPrefix := "AVALOND"
@@ -125,7 +211,14 @@ func init() {
 		config.FlagDatabaseUsername: config.EnvDatabaseUsername,
 		config.FlagDatabasePassword: config.EnvDatabasePassword,
 		config.FlagDatabaseName:     config.EnvDatabaseName,
+		config.FlagDatabasePort:     config.EnvDatabasePort,
+		config.FlagDatabaseSSLMode:  config.EnvDatabaseSSLMode,
 		config.FlagBlueprintVersion: config.EnvBlueprintVersion,
+		config.FlagBlueprintCache:   config.EnvBlueprintCache,
+		config.FlagSnapshotDir:      config.EnvSnapshotDir,
+		config.FlagDefaultLocale:    config.EnvDefaultLocale,
+		config.FlagAssetStore:       config.EnvAssetStore,
+		config.FlagAssetDir:         config.EnvAssetDir,
 	}
 
 	for flag, env := range bindFlags {
@@ -144,6 +237,15 @@ func init() {
 	viper.SetDefault("author", "Alfred Dobradi <alfreddobradi@gmail.com>")
 	viper.SetDefault("license", "MIT")
 
+	startCmd.Flags().String(config.FlagBlueprintDir, "", "directory of blueprint files to sync into the dev version")
+	if err := viper.BindPFlag(config.FlagBlueprintDir, startCmd.Flags().Lookup(config.FlagBlueprintDir)); err != nil {
+		slog.Warn("failed to bind flag", "error", err, "name", config.FlagBlueprintDir)
+	}
+
+	if err := viper.BindEnv(config.FlagBlueprintDir, fmt.Sprintf("%s_%s", envPrefix, config.EnvBlueprintDir)); err != nil {
+		slog.Warn("failed to bind env", "error", err, "flag", config.FlagBlueprintDir)
+	}
+
 	rootCmd.AddCommand(startCmd)
 }
 
@@ -168,6 +270,107 @@ func initConfig() {
 	setConfigs()
 }
 
+// followChannel switches the configured blueprint version to the one promoted
+// to the channel this environment follows.
+func followChannel(ctx context.Context, channel metadata.State) {
+	store, err := metadata.Get()
+	if err != nil {
+		slog.Warn("failed to open metadata store, using configured blueprint version", "error", err, "channel", channel)
+		return
+	}
+
+	version, err := store.GetChannel(ctx, channel)
+	if err != nil {
+		slog.Warn("no version promoted to channel, using configured blueprint version", "error", err, "channel", channel)
+		return
+	}
+
+	slog.Info("following blueprint channel", "channel", channel, "version", version)
+
+	cluster.SetActive(version)
+	cache.SetVersion(version)
+}
+
+// channelVersion returns the version promoted to the channel this environment
+// follows, or the configured version.
+func channelVersion(ctx context.Context, channel metadata.State) string {
+	version := cluster.Active()
+	if channel == "" {
+		return version
+	}
+
+	store, err := metadata.Get()
+	if err != nil {
+		return version
+	}
+
+	if promoted, err := store.GetChannel(ctx, channel); err == nil {
+		return promoted
+	}
+
+	return version
+}
+
+// startWebhooks sets up delivery of blueprint events to the subscriptions
+// listed under webhooks in the config file.
+func startWebhooks() error {
+	var subscriptions []webhook.Subscription
+	if err := viper.UnmarshalKey(config.FlagWebhooks, &subscriptions); err != nil {
+		return err
+	}
+
+	for _, sub := range subscriptions {
+		if err := sub.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if len(subscriptions) > 0 {
+		webhook.SetDispatcher(webhook.NewDispatcher(subscriptions))
+		slog.Info("delivering blueprint events to webhooks", "subscriptions", len(subscriptions))
+	}
+
+	return nil
+}
+
+// drainWebhooks waits for the deliveries in flight before shutting down, up
+// to webhook.DrainTimeout.
+func drainWebhooks() {
+	d := webhook.Default()
+	if d == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhook.DrainTimeout)
+	defer cancel()
+
+	if err := d.Drain(ctx); err != nil {
+		slog.Warn("abandoned webhook deliveries on shutdown", "error", err)
+	}
+}
+
+// bootFromSnapshot serves the last loaded version from disk after loading the
+// cache failed with cause.
+func bootFromSnapshot(cause error) error {
+	dir := viper.GetString(config.FlagSnapshotDir)
+	if dir == "" {
+		return cause
+	}
+
+	snap, err := snapshot.Active(dir)
+	if err != nil {
+		return errors.Join(cause, fmt.Errorf("snapshot: %w", err))
+	}
+
+	slog.Warn("registry is unreachable, serving stale blueprint snapshot", "error", cause, "version", snap.Version, "saved_at", snap.SavedAt)
+
+	cluster.SetActive(snap.Version)
+	cache.SetVersion(snap.Version)
+	snapshot.Serve(snap, cause)
+
+	return nil
+}
+
 func initMessageBus() (*transport.Connection, error) {
 	natsAddress := viper.GetString(config.FlagNatsAddress)
 	if natsAddress == "" {
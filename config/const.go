@@ -17,7 +17,20 @@ const (
 	EnvDatabaseUsername string = "DB_USERNAME"
 	EnvDatabasePassword string = "DB_PASSWORD"
 	EnvDatabaseName     string = "DB_DATABASE"
+	EnvDatabasePort     string = "DB_PORT"
+	EnvDatabaseSSLMode  string = "DB_SSLMODE"
 	EnvBlueprintVersion string = "BLUEPRINT_VERSION"
+	EnvBlueprintDir     string = "BLUEPRINT_DIR"
+	EnvBlueprintCache   string = "BLUEPRINT_CACHE_SIZE"
+	EnvSnapshotDir      string = "BLUEPRINT_SNAPSHOT_DIR"
+	EnvDefaultLocale    string = "DEFAULT_LOCALE"
+	EnvAssetStore       string = "ASSET_STORE"
+	EnvAssetDir         string = "ASSET_DIR"
+	EnvRegistryServer   string = "REGISTRY_SERVER"
+	EnvToken            string = "TOKEN"
+	EnvTokenURL         string = "TOKEN_URL"
+	EnvClientID         string = "CLIENT_ID"
+	EnvClientSecret     string = "CLIENT_SECRET"
 
 	FlagEnvironment      string = "environment"
 	FlagLogLevel         string = "log-level"
@@ -33,5 +46,19 @@ const (
 	FlagDatabaseUsername string = "db-username"
 	FlagDatabasePassword string = "db-password"
 	FlagDatabaseName     string = "db-name"
+	FlagDatabasePort     string = "db-port"
+	FlagDatabaseSSLMode  string = "db-sslmode"
 	FlagBlueprintVersion string = "blueprint-version"
+	FlagBlueprintDir     string = "blueprint-dir"
+	FlagBlueprintCache   string = "blueprint-cache-size"
+	FlagSnapshotDir      string = "blueprint-snapshot-dir"
+	FlagDefaultLocale    string = "default-locale"
+	FlagAssetStore       string = "asset-store"
+	FlagAssetDir         string = "asset-dir"
+	FlagWebhooks         string = "webhooks"
+	FlagRegistryServer   string = "server"
+	FlagToken            string = "token"
+	FlagTokenURL         string = "token-url"
+	FlagClientID         string = "client-id"
+	FlagClientSecret     string = "client-secret"
 )
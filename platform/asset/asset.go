@@ -0,0 +1,159 @@
+package asset
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/spf13/viper"
+)
+
+const (
+	KindFilesystem = "filesystem"
+	KindMemory     = "memory"
+)
+
+var (
+	ErrNotFound    = errors.New("asset not found")
+	ErrInvalidName = errors.New("invalid asset name")
+	ErrDisabled    = errors.New("asset hosting is disabled")
+)
+
+// validName keeps every part of an asset's key usable as a path segment.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Asset describes a file attached to a blueprint, such as its icon.
+type Asset struct {
+	Version     string    `json:"version"`
+	Kind        string    `json:"kind"`
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Hash        string    `json:"hash"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func New(version, kind, slug, name, contentType string) (*Asset, error) {
+	for _, part := range []string{version, kind, slug, name} {
+		if !validName.MatchString(part) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidName, part)
+		}
+	}
+
+	return &Asset{
+		Version:     version,
+		Kind:        kind,
+		Slug:        slug,
+		Name:        name,
+		ContentType: contentType,
+	}, nil
+}
+
+// ETag is the entity tag of the asset's content.
+func (a *Asset) ETag() string {
+	return `"` + a.Hash + `"`
+}
+
+// hashingReader computes the hash and size of what is read through it.
+type hashingReader struct {
+	r    io.Reader
+	hash hash.Hash
+	size int64
+}
+
+func newHashingReader(r io.Reader) *hashingReader {
+	return &hashingReader{r: r, hash: sha256.New()}
+}
+
+func (h *hashingReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	h.hash.Write(p[:n])
+	h.size += int64(n)
+
+	return n, err
+}
+
+// finish records the hash and size of the content on a.
+func (h *hashingReader) finish(a *Asset) {
+	a.Hash = hex.EncodeToString(h.hash.Sum(nil))
+	a.Size = h.size
+	a.UpdatedAt = time.Now().UTC()
+}
+
+type Store interface {
+	// Put stores the content of an asset, replacing the previous one. The
+	// hash, size and update time of a are set from the content.
+	Put(ctx context.Context, a *Asset, content io.Reader) error
+
+	// Get returns the asset and its content, which the caller closes.
+	Get(ctx context.Context, version, kind, slug, name string) (*Asset, io.ReadSeekCloser, error)
+
+	// List returns the assets of a blueprint sorted by name.
+	List(ctx context.Context, version, kind, slug string) ([]*Asset, error)
+
+	// Delete removes an asset. It returns ErrNotFound if there was nothing to
+	// delete.
+	Delete(ctx context.Context, version, kind, slug, name string) error
+}
+
+var (
+	_ Store = (*FilesystemStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
+
+var (
+	kind  = KindFilesystem
+	store Store
+	mx    = &sync.Mutex{}
+)
+
+// SetKind selects the backend.
+func SetKind(k string) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	switch k {
+	case KindFilesystem, KindMemory:
+		if k != kind {
+			store = nil
+		}
+
+		kind = k
+	}
+}
+
+// Get returns the configured store. The filesystem store returns ErrDisabled
+// without an asset directory.
+func Get() (Store, error) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	if store != nil {
+		return store, nil
+	}
+
+	switch kind {
+	case KindMemory:
+		store = NewMemoryStore()
+	case KindFilesystem:
+		dir := viper.GetString(config.FlagAssetDir)
+		if dir == "" {
+			return nil, ErrDisabled
+		}
+
+		store = NewFilesystemStore(dir)
+	default:
+		return nil, fmt.Errorf("invalid asset store")
+	}
+
+	return store, nil
+}
@@ -0,0 +1,86 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]Store{
+		KindFilesystem: NewFilesystemStore(t.TempDir()),
+		KindMemory:     NewMemoryStore(),
+	}
+
+	content := []byte("\x89PNG\r\n\x1a\nicon")
+	sum := sha256.Sum256(content)
+
+	for label, store := range stores {
+		tf := func(t *testing.T) {
+			ctx := context.Background()
+
+			a, err := New("1.0.0", "building", "house", "icon.png", "image/png")
+			require.NoError(t, err)
+			require.NoError(t, store.Put(ctx, a, bytes.NewReader(content)))
+
+			assert.Equal(t, hex.EncodeToString(sum[:]), a.Hash)
+			assert.Equal(t, int64(len(content)), a.Size)
+			assert.False(t, a.UpdatedAt.IsZero())
+
+			other, err := New("1.0.0", "building", "house", "art.jpg", "image/jpeg")
+			require.NoError(t, err)
+			require.NoError(t, store.Put(ctx, other, bytes.NewReader([]byte("art"))))
+
+			got, body, err := store.Get(ctx, "1.0.0", "building", "house", "icon.png")
+			require.NoError(t, err)
+
+			raw, err := io.ReadAll(body)
+			require.NoError(t, err)
+			require.NoError(t, body.Close())
+
+			assert.Equal(t, content, raw)
+			assert.Equal(t, a.Hash, got.Hash)
+			assert.Equal(t, "image/png", got.ContentType)
+
+			assets, err := store.List(ctx, "1.0.0", "building", "house")
+			require.NoError(t, err)
+			require.Len(t, assets, 2)
+			assert.Equal(t, "art.jpg", assets[0].Name)
+			assert.Equal(t, "icon.png", assets[1].Name)
+
+			assets, err = store.List(ctx, "1.0.0", "building", "hut")
+			require.NoError(t, err)
+			assert.Empty(t, assets)
+
+			require.NoError(t, store.Delete(ctx, "1.0.0", "building", "house", "art.jpg"))
+			assert.ErrorIs(t, store.Delete(ctx, "1.0.0", "building", "house", "art.jpg"), ErrNotFound)
+
+			_, _, err = store.Get(ctx, "1.0.0", "building", "house", "art.jpg")
+			assert.ErrorIs(t, err, ErrNotFound)
+		}
+
+		t.Run(label, tf)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"", "..", ".hidden", "a/b", `a\b`, "icon png"} {
+		_, err := New("1.0.0", "building", "house", name, "image/png")
+		assert.ErrorIs(t, err, ErrInvalidName, name)
+	}
+
+	_, err := New("../1.0.0", "building", "house", "icon.png", "image/png")
+	assert.ErrorIs(t, err, ErrInvalidName)
+
+	a, err := New("1.0.0", "building", "house", "icon-64.png", "image/png")
+	require.NoError(t, err)
+
+	a.Hash = "abc"
+	assert.Equal(t, `"abc"`, a.ETag())
+}
@@ -0,0 +1,220 @@
+package asset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FilesystemStore keeps assets in a directory, one directory per blueprint.
+// The description of an asset is kept next to its content, in a hidden JSON
+// file asset names can't collide with.
+type FilesystemStore struct {
+	mx  *sync.RWMutex
+	dir string
+}
+
+func NewFilesystemStore(dir string) *FilesystemStore {
+	return &FilesystemStore{
+		mx:  &sync.RWMutex{},
+		dir: dir,
+	}
+}
+
+func (s *FilesystemStore) blueprintDir(version, kind, slug string) string {
+	return filepath.Join(s.dir, version, kind, slug)
+}
+
+func (s *FilesystemStore) paths(version, kind, slug, name string) (string, string, error) {
+	if _, err := New(version, kind, slug, name, ""); err != nil {
+		return "", "", err
+	}
+
+	dir := s.blueprintDir(version, kind, slug)
+
+	return filepath.Join(dir, name), filepath.Join(dir, "."+name+".json"), nil
+}
+
+func (s *FilesystemStore) Put(ctx context.Context, a *Asset, content io.Reader) error {
+	path, meta, err := s.paths(a.Version, a.Kind, a.Slug, a.Name)
+	if err != nil {
+		return err
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".asset-*")
+	if err != nil {
+		return err
+	}
+
+	hr := newHashingReader(content)
+
+	if _, err := io.Copy(tmp, hr); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	hr.finish(a)
+
+	raw, err := json.Marshal(a)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return writeFile(meta, raw)
+}
+
+func (s *FilesystemStore) Get(ctx context.Context, version, kind, slug, name string) (*Asset, io.ReadSeekCloser, error) {
+	path, meta, err := s.paths(version, kind, slug, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	a, err := readAsset(meta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return a, f, nil
+}
+
+func (s *FilesystemStore) List(ctx context.Context, version, kind, slug string) ([]*Asset, error) {
+	if _, err := New(version, kind, slug, "list", ""); err != nil {
+		return nil, err
+	}
+
+	dir := s.blueprintDir(version, kind, slug)
+
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*Asset{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	assets := make([]*Asset, 0, len(entries)/2)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") || strings.HasPrefix(name, ".asset-") {
+			continue
+		}
+
+		a, err := readAsset(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, a)
+	}
+
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Name < assets[j].Name })
+
+	return assets, nil
+}
+
+func (s *FilesystemStore) Delete(ctx context.Context, version, kind, slug, name string) error {
+	path, meta, err := s.paths(version, kind, slug, name)
+	if err != nil {
+		return err
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := os.Remove(meta); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func readAsset(path string) (*Asset, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var a Asset
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func writeFile(path string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".asset-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,102 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"sync"
+)
+
+type memoryAsset struct {
+	asset   Asset
+	content []byte
+}
+
+// MemoryStore keeps assets in memory, for tests and local development.
+type MemoryStore struct {
+	mx     *sync.RWMutex
+	assets map[string]memoryAsset
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mx:     &sync.RWMutex{},
+		assets: make(map[string]memoryAsset),
+	}
+}
+
+func blueprintKey(version, kind, slug string) string {
+	return version + "/" + kind + "/" + slug + "/"
+}
+
+func (s *MemoryStore) Put(ctx context.Context, a *Asset, content io.Reader) error {
+	hr := newHashingReader(content)
+
+	raw, err := io.ReadAll(hr)
+	if err != nil {
+		return err
+	}
+
+	hr.finish(a)
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.assets[blueprintKey(a.Version, a.Kind, a.Slug)+a.Name] = memoryAsset{asset: *a, content: raw}
+
+	return nil
+}
+
+type nopSeekCloser struct {
+	*bytes.Reader
+}
+
+func (nopSeekCloser) Close() error { return nil }
+
+func (s *MemoryStore) Get(ctx context.Context, version, kind, slug, name string) (*Asset, io.ReadSeekCloser, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	stored, ok := s.assets[blueprintKey(version, kind, slug)+name]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+
+	a := stored.asset
+
+	return &a, nopSeekCloser{bytes.NewReader(stored.content)}, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, version, kind, slug string) ([]*Asset, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	prefix := blueprintKey(version, kind, slug)
+	assets := make([]*Asset, 0)
+
+	for key, stored := range s.assets {
+		if len(key) > len(prefix) && key[:len(prefix)] == prefix {
+			a := stored.asset
+			assets = append(assets, &a)
+		}
+	}
+
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Name < assets[j].Name })
+
+	return assets, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, version, kind, slug, name string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	key := blueprintKey(version, kind, slug) + name
+	if _, ok := s.assets[key]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.assets, key)
+
+	return nil
+}
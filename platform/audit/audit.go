@@ -0,0 +1,220 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	"github.com/google/uuid"
+)
+
+const (
+	ActionSave   = "save"
+	ActionReload = "reload"
+
+	// DefaultLimit and MaxLimit bound the number of records a query returns.
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Record is a single entry of the audit trail. Saves name the blueprint and
+// hash its new content, reloads only the version and how the reload went.
+// Saves are recorded before they're written, one that fails is followed by a
+// record with a status.
+type Record struct {
+	ID        string    `json:"id"`
+	Action    string    `json:"action"`
+	Subject   string    `json:"subject"`
+	Version   string    `json:"version"`
+	Kind      string    `json:"kind,omitempty"`
+	Slug      string    `json:"slug,omitempty"`
+	Hash      string    `json:"hash,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Diff      []Change  `json:"diff,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewRecord(action, subject, version string) *Record {
+	return &Record{
+		ID:        uuid.NewString(),
+		Action:    action,
+		Subject:   subject,
+		Version:   version,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// Change is a field of a blueprint that a save added, removed or changed.
+// Before is missing for added fields and After for removed ones.
+type Change struct {
+	Path   string `json:"path"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Diff compares two blueprints decoded from JSON field by field. Nested
+// objects are compared recursively, lists as a whole. A nil before diffs
+// against an empty blueprint.
+func Diff(before, after any) []Change {
+	changes := make([]Change, 0)
+	diffValue("", before, after, &changes)
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return changes
+}
+
+func diffValue(path string, before, after any, changes *[]Change) {
+	beforeMap, beforeOK := before.(map[string]any)
+	afterMap, afterOK := after.(map[string]any)
+
+	if before == nil && afterOK {
+		beforeMap, beforeOK = map[string]any{}, true
+	}
+
+	if after == nil && beforeOK {
+		afterMap, afterOK = map[string]any{}, true
+	}
+
+	if !beforeOK || !afterOK {
+		if !reflect.DeepEqual(before, after) {
+			*changes = append(*changes, Change{Path: path, Before: before, After: after})
+		}
+
+		return
+	}
+
+	for key, value := range afterMap {
+		diffValue(join(path, key), beforeMap[key], value, changes)
+	}
+
+	for key, value := range beforeMap {
+		if _, ok := afterMap[key]; !ok {
+			diffValue(join(path, key), value, nil, changes)
+		}
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// Hash returns the hex-encoded SHA-256 of v encoded as JSON. Object keys are
+// sorted, so equal blueprints hash the same.
+func Hash(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Filter narrows down a query. Empty fields match every record.
+type Filter struct {
+	Subject string
+	Version string
+	Kind    string
+	Slug    string
+	Action  string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+func (f Filter) match(r *Record) bool {
+	switch {
+	case f.Subject != "" && r.Subject != f.Subject,
+		f.Version != "" && r.Version != f.Version,
+		f.Kind != "" && r.Kind != f.Kind,
+		f.Slug != "" && r.Slug != f.Slug,
+		f.Action != "" && r.Action != f.Action,
+		!f.Since.IsZero() && r.CreatedAt.Before(f.Since),
+		!f.Until.IsZero() && !r.CreatedAt.Before(f.Until):
+		return false
+	}
+
+	return true
+}
+
+func (f Filter) limit() int {
+	switch {
+	case f.Limit <= 0:
+		return DefaultLimit
+	case f.Limit > MaxLimit:
+		return MaxLimit
+	default:
+		return f.Limit
+	}
+}
+
+// Store is an append-only log of records.
+type Store interface {
+	Append(ctx context.Context, record *Record) error
+
+	// List returns the records matching filter, newest first.
+	List(ctx context.Context, filter Filter) ([]*Record, error)
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*CockroachStore)(nil)
+)
+
+var (
+	kind  = database.DriverCockroach
+	store Store
+	mx    = &sync.Mutex{}
+)
+
+// SetKind selects the backend with the same driver names as the kit's
+// database package. The store opened before is dropped, a memory store starts
+// out empty again.
+func SetKind(driver string) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	switch driver {
+	case database.DriverCockroach, database.DriverMock:
+		store = nil
+		kind = driver
+	}
+}
+
+func Get() (Store, error) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	if store != nil {
+		return store, nil
+	}
+
+	switch kind {
+	case database.DriverMock:
+		store = NewMemoryStore()
+	case database.DriverCockroach:
+		s, err := NewCockroachStore(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		store = s
+	default:
+		return nil, fmt.Errorf("invalid audit driver")
+	}
+
+	return store, nil
+}
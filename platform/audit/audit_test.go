@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]any{
+		"name":       "House",
+		"slug":       "house",
+		"build_time": "10s",
+		"cost":       []any{map[string]any{"resource": "wood", "amount": 5.0}},
+		"meta":       map[string]any{"tier": 1.0, "tag": "old"},
+	}
+
+	after := map[string]any{
+		"name":       "Big House",
+		"slug":       "house",
+		"build_time": "10s",
+		"cost":       []any{map[string]any{"resource": "wood", "amount": 8.0}},
+		"meta":       map[string]any{"tier": 1.0},
+		"upkeep":     2.0,
+	}
+
+	assert.Equal(t, []Change{
+		{Path: "cost", Before: before["cost"], After: after["cost"]},
+		{Path: "meta.tag", Before: "old"},
+		{Path: "name", Before: "House", After: "Big House"},
+		{Path: "upkeep", After: 2.0},
+	}, Diff(before, after))
+
+	assert.Empty(t, Diff(after, after))
+
+	added := Diff(nil, map[string]any{"name": "House", "slug": "house"})
+	assert.Equal(t, []Change{{Path: "name", After: "House"}, {Path: "slug", After: "house"}}, added)
+}
+
+func TestHash(t *testing.T) {
+	a, err := Hash(map[string]any{"name": "House", "slug": "house"})
+	require.NoError(t, err)
+
+	b, err := Hash(map[string]any{"slug": "house", "name": "House"})
+	require.NoError(t, err)
+
+	c, err := Hash(map[string]any{"slug": "house", "name": "Hut"})
+	require.NoError(t, err)
+
+	assert.Len(t, a, 64)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	start := time.Now().UTC()
+
+	for i, slug := range []string{"house", "farm", "house"} {
+		record := NewRecord(ActionSave, "alice", "1.0.0")
+		record.Kind = "building"
+		record.Slug = slug
+		record.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+
+		require.NoError(t, store.Append(ctx, record))
+	}
+
+	reload := NewRecord(ActionReload, "bob", "1.0.0")
+	reload.CreatedAt = start.Add(3 * time.Minute)
+	require.NoError(t, store.Append(ctx, reload))
+
+	tests := []struct {
+		label    string
+		filter   Filter
+		expected []string
+	}{
+		{label: "all", expected: []string{"", "house", "farm", "house"}},
+		{label: "slug", filter: Filter{Slug: "house"}, expected: []string{"house", "house"}},
+		{label: "subject", filter: Filter{Subject: "bob"}, expected: []string{""}},
+		{label: "action", filter: Filter{Action: ActionSave, Limit: 2}, expected: []string{"house", "farm"}},
+		{label: "window", filter: Filter{Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute)}, expected: []string{"house", "farm"}},
+		{label: "version", filter: Filter{Version: "2.0.0"}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			records, err := store.List(ctx, tt.filter)
+			require.NoError(t, err)
+
+			slugs := make([]string, 0, len(records))
+			for _, r := range records {
+				slugs = append(slugs, r.Slug)
+			}
+
+			assert.Equal(t, tt.expected, slugs)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
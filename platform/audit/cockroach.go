@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cockroach"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS blueprint_audit (
+		id UUID PRIMARY KEY,
+		action STRING NOT NULL,
+		subject STRING NOT NULL DEFAULT '',
+		version STRING NOT NULL,
+		kind STRING NOT NULL DEFAULT '',
+		slug STRING NOT NULL DEFAULT '',
+		hash STRING NOT NULL DEFAULT '',
+		status STRING NOT NULL DEFAULT '',
+		diff JSONB NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		INDEX blueprint_audit_created_at_idx (created_at DESC),
+		INDEX blueprint_audit_blueprint_idx (version, kind, slug, created_at DESC)
+	)`,
+}
+
+var recordColumns = []string{"id", "action", "subject", "version", "kind", "slug", "hash", "status", "diff", "created_at"}
+
+// CockroachStore keeps records in the registry database. It only ever inserts
+// rows.
+type CockroachStore struct {
+	pool *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewCockroachStore(ctx context.Context) (*CockroachStore, error) {
+	pool, err := cockroach.Pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range schema {
+		if _, err := pool.Exec(ctx, statement); err != nil {
+			return nil, fmt.Errorf("schema: %w", err)
+		}
+	}
+
+	return &CockroachStore{
+		pool: pool,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}, nil
+}
+
+func (s *CockroachStore) Append(ctx context.Context, record *Record) error {
+	var diff any
+	if len(record.Diff) > 0 {
+		raw, err := json.Marshal(record.Diff)
+		if err != nil {
+			return err
+		}
+
+		diff = string(raw)
+	}
+
+	query, params, err := s.psql.Insert("blueprint_audit").
+		Columns(recordColumns...).
+		Values(record.ID, record.Action, record.Subject, record.Version, record.Kind, record.Slug, record.Hash, record.Status, diff, record.CreatedAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, query, params...)
+
+	return err
+}
+
+func (s *CockroachStore) List(ctx context.Context, filter Filter) ([]*Record, error) {
+	where := sq.Eq{}
+
+	for column, value := range map[string]string{
+		"subject": filter.Subject,
+		"version": filter.Version,
+		"kind":    filter.Kind,
+		"slug":    filter.Slug,
+		"action":  filter.Action,
+	} {
+		if value != "" {
+			where[column] = value
+		}
+	}
+
+	builder := s.psql.Select(recordColumns...).
+		From("blueprint_audit").
+		Where(where).
+		OrderBy("created_at DESC").
+		Limit(uint64(filter.limit()))
+
+	if !filter.Since.IsZero() {
+		builder = builder.Where(sq.GtOrEq{"created_at": filter.Since})
+	}
+
+	if !filter.Until.IsZero() {
+		builder = builder.Where(sq.Lt{"created_at": filter.Until})
+	}
+
+	query, params, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*Record, 0)
+
+	for rows.Next() {
+		var (
+			r    Record
+			diff []byte
+		)
+
+		if err := rows.Scan(&r.ID, &r.Action, &r.Subject, &r.Version, &r.Kind, &r.Slug, &r.Hash, &r.Status, &diff, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		if len(diff) > 0 {
+			if err := json.Unmarshal(diff, &r.Diff); err != nil {
+				return nil, fmt.Errorf("scan: %w", err)
+			}
+		}
+
+		records = append(records, &r)
+	}
+
+	return records, rows.Err()
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps records in memory, for tests and local development.
+type MemoryStore struct {
+	mx      *sync.RWMutex
+	records []Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mx:      &sync.RWMutex{},
+		records: make([]Record, 0),
+	}
+}
+
+func (s *MemoryStore) Append(ctx context.Context, record *Record) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.records = append(s.records, *record)
+
+	return nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, filter Filter) ([]*Record, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	limit := filter.limit()
+	records := make([]*Record, 0)
+
+	for i := len(s.records) - 1; i >= 0 && len(records) < limit; i-- {
+		if filter.match(&s.records[i]) {
+			r := s.records[i]
+			records = append(records, &r)
+		}
+	}
+
+	return records, nil
+}
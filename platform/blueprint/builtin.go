@@ -0,0 +1,85 @@
+package blueprint
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
+)
+
+const (
+	KindBuilding = "building"
+	KindResource = "resource"
+)
+
+func init() {
+	Register(&Kind{
+		Name:       KindBuilding,
+		Collection: "buildings",
+		Decode:     Decoder[registry.BuildingBlueprintRequest](),
+		Save:       Saver(SaveBuilding),
+		Lookup:     Lookup(registry.GetBuildingBlueprint),
+		Delete:     Deleter(Writer.DeleteBuildingBlueprint),
+		Cached:     Cached(cache.GetBuildingBlueprint),
+		List:       Lister(ListBuildings, BuildingRequestFromProto),
+		Loaded:     Loaded[*proto.BuildingBlueprint]("buildings"),
+		Read:       Reader(ListBuildings),
+		New:        func() Blueprint { return &proto.BuildingBlueprint{} },
+		Request:    Requester(BuildingRequestFromProto),
+		Model:      registry.BuildingBlueprintRequest{},
+		Required:   []string{"name", "slug"},
+		Durations:  []string{"build_time", "production_time"},
+	})
+
+	Register(&Kind{
+		Name:       KindResource,
+		Collection: "resources",
+		Decode:     Decoder[registry.ResourceBlueprintRequest](),
+		Save:       Saver(SaveResource),
+		Lookup:     Lookup(registry.GetResourceBlueprint),
+		Delete:     Deleter(Writer.DeleteResourceBlueprint),
+		Cached:     Cached(cache.GetResourceBlueprint),
+		List:       Lister(ListResources, ResourceRequestFromProto),
+		Loaded:     Loaded[*proto.ResourceBlueprint]("resources"),
+		Read:       Reader(ListResources),
+		New:        func() Blueprint { return &proto.ResourceBlueprint{} },
+		Request:    Requester(ResourceRequestFromProto),
+		Model:      registry.ResourceBlueprintRequest{},
+		Required:   []string{"name", "slug"},
+	})
+}
+
+// ListBuildings reads the building blueprints of a version from the
+// registry. Versions without buildings list none.
+func ListBuildings(ctx context.Context, version string) ([]*proto.BuildingBlueprint, error) {
+	db, err := database.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	bps, err := db.GetBuildingBlueprints(ctx, version)
+	if errors.Is(registryError(err), ErrNotFound) {
+		return make([]*proto.BuildingBlueprint, 0), nil
+	}
+
+	return bps, err
+}
+
+// ListResources reads the resource blueprints of a version from the
+// registry. Versions without resources list none.
+func ListResources(ctx context.Context, version string) ([]*proto.ResourceBlueprint, error) {
+	db, err := database.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	bps, err := db.GetResourceBlueprints(ctx, version)
+	if errors.Is(registryError(err), ErrNotFound) {
+		return make([]*proto.ResourceBlueprint, 0), nil
+	}
+
+	return bps, err
+}
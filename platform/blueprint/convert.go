@@ -0,0 +1,107 @@
+package blueprint
+
+import (
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func BuildingRequestFromProto(bp *proto.BuildingBlueprint) registry.BuildingBlueprintRequest {
+	production := make([]registry.Production, 0, len(bp.GetProduction()))
+
+	for _, item := range bp.GetProduction() {
+		production = append(production, registry.Production{
+			Cost:           resourceListFromProto(item.GetCost()),
+			Product:        resourceListFromProto(item.GetOutput()),
+			ProductionTime: item.GetProductionTime().AsDuration().String(),
+		})
+	}
+
+	return registry.BuildingBlueprintRequest{
+		Name:       bp.GetName(),
+		Slug:       bp.GetSlug(),
+		BuildTime:  bp.GetBuildTime().AsDuration().String(),
+		Cost:       resourceListFromProto(bp.GetCost()),
+		Production: production,
+	}
+}
+
+func ResourceRequestFromProto(bp *proto.ResourceBlueprint) registry.ResourceBlueprintRequest {
+	return registry.ResourceBlueprintRequest{
+		Name: bp.GetName(),
+		Slug: bp.GetSlug(),
+	}
+}
+
+// BuildingProtoFromRequest builds the blueprint the kit registry stores for
+// req in version.
+func BuildingProtoFromRequest(version string, req registry.BuildingBlueprintRequest) (*proto.BuildingBlueprint, error) {
+	buildTime, err := time.ParseDuration(req.BuildTime)
+	if err != nil {
+		return nil, err
+	}
+
+	production := make([]*proto.Production, 0, len(req.Production))
+
+	for _, item := range req.Production {
+		productionTime, err := time.ParseDuration(item.ProductionTime)
+		if err != nil {
+			return nil, err
+		}
+
+		production = append(production, &proto.Production{
+			Cost:           resourceListToProto(item.Cost),
+			Output:         resourceListToProto(item.Product),
+			ProductionTime: durationpb.New(productionTime),
+		})
+	}
+
+	return &proto.BuildingBlueprint{
+		ID:         registry.ID(req, version).String(),
+		Name:       req.Name,
+		Slug:       req.Slug,
+		Version:    version,
+		BuildTime:  durationpb.New(buildTime),
+		Cost:       resourceListToProto(req.Cost),
+		Production: production,
+	}, nil
+}
+
+// ResourceProtoFromRequest builds the blueprint the kit registry stores for
+// req in version.
+func ResourceProtoFromRequest(version string, req registry.ResourceBlueprintRequest) *proto.ResourceBlueprint {
+	return &proto.ResourceBlueprint{
+		ID:      registry.ID(req, version).String(),
+		Name:    req.Name,
+		Slug:    req.Slug,
+		Version: version,
+	}
+}
+
+func resourceListFromProto(list *proto.ResourceList) registry.ResourceList {
+	items := make(registry.ResourceList, 0, len(list.GetResources()))
+
+	for _, item := range list.GetResources() {
+		items = append(items, registry.ResourceListItem{
+			Resource: item.GetName(),
+			Amount:   item.GetAmount(),
+		})
+	}
+
+	return items
+}
+
+func resourceListToProto(list registry.ResourceList) *proto.ResourceList {
+	items := make([]*proto.ResourceListItem, 0, len(list))
+
+	for _, item := range list {
+		items = append(items, &proto.ResourceListItem{
+			Name:   item.Resource,
+			Amount: item.Amount,
+		})
+	}
+
+	return &proto.ResourceList{Resources: items}
+}
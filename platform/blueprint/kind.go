@@ -0,0 +1,272 @@
+package blueprint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	ErrUnknownKind = errors.New("unknown blueprint kind")
+	ErrNotFound    = errors.New("blueprint not found")
+)
+
+type (
+	// DecodeFunc decodes the JSON body of a blueprint request.
+	DecodeFunc func(raw []byte) (registry.Request, error)
+
+	// ValidateFunc checks a decoded definition before it is saved.
+	ValidateFunc func(def registry.Request) error
+
+	// SaveFunc writes a definition to the registry. Forced saves replace the
+	// stored definition with the same slug.
+	SaveFunc func(ctx context.Context, version string, def registry.Request, force bool) error
+
+	// LookupFunc reads a single blueprint of a version from the registry. It
+	// returns ErrNotFound if there is none.
+	LookupFunc func(ctx context.Context, version, slug string) (any, error)
+
+	// DeleteFunc removes a single blueprint of a version from the registry. It
+	// returns ErrNotFound if there is none.
+	DeleteFunc func(ctx context.Context, version, slug string) error
+
+	// CachedFunc reads a single blueprint of the active version from the cache.
+	CachedFunc func(ctx context.Context, slug string) (any, bool)
+
+	// ListFunc reads every blueprint of a version from the registry, in the
+	// shape they are uploaded in.
+	ListFunc func(ctx context.Context, version string) ([]registry.Request, error)
+)
+
+// Kind describes everything the gateway needs to handle one kind of
+// blueprint. Cached, List and Delete are optional, kinds without Cached are
+// always looked up in the registry and kinds without Delete can't be deleted.
+//
+// Loaded, Read, New and Request are optional too, but go together. Kinds with
+// them are held in sets: the versions served whole, exports, snapshots and the
+// lookups on the message bus.
+//
+// Model is a zero value of the request type. It is reflected into the JSON
+// Schema of the kind, which every uploaded body is validated against. Required
+// lists the mandatory body fields and Durations the fields holding a Go
+// duration.
+type Kind struct {
+	Name       string
+	Collection string
+
+	Decode   DecodeFunc
+	Validate ValidateFunc
+	Save     SaveFunc
+	Lookup   LookupFunc
+	Delete   DeleteFunc
+	Cached   CachedFunc
+	List     ListFunc
+
+	Loaded  LoadedFunc
+	Read    ReadFunc
+	New     NewFunc
+	Request RequestFunc
+
+	Model     registry.Request
+	Required  []string
+	Durations []string
+
+	schemaOnce sync.Once
+	schema     []byte
+	compiled   *jsonschema.Schema
+	schemaErr  error
+}
+
+var (
+	mx    = &sync.RWMutex{}
+	kinds = make(map[string]*Kind)
+)
+
+// Register makes a kind available to the API. It panics if the kind is
+// incomplete or its name or collection is already taken.
+func Register(kind *Kind) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	if kind == nil || kind.Name == "" || kind.Decode == nil || kind.Save == nil || kind.Lookup == nil {
+		panic("blueprint: incomplete kind registration")
+	}
+
+	held := kind.Loaded != nil
+	if (kind.Read != nil) != held || (kind.New != nil) != held || (kind.Request != nil) != held {
+		panic(fmt.Sprintf("blueprint: kind %s needs all of Loaded, Read, New and Request or none", kind.Name))
+	}
+
+	if kind.Collection == "" {
+		kind.Collection = kind.Name + "s"
+	}
+
+	for _, registered := range kinds {
+		if registered.Name == kind.Name || registered.Collection == kind.Collection ||
+			registered.Name == kind.Collection || registered.Collection == kind.Name {
+			panic(fmt.Sprintf("blueprint: kind %s registered twice", kind.Name))
+		}
+	}
+
+	kinds[kind.Name] = kind
+}
+
+// Get returns the kind registered under name.
+func Get(name string) (*Kind, error) {
+	mx.RLock()
+	defer mx.RUnlock()
+
+	kind, ok := kinds[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, name)
+	}
+
+	return kind, nil
+}
+
+// Find returns the kind whose name or collection matches key.
+func Find(key string) (*Kind, error) {
+	if kind, err := Get(key); err == nil {
+		return kind, nil
+	}
+
+	mx.RLock()
+	defer mx.RUnlock()
+
+	for _, kind := range kinds {
+		if kind.Collection == key {
+			return kind, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKind, key)
+}
+
+// Kinds lists the registered kinds sorted by name.
+func Kinds() []*Kind {
+	mx.RLock()
+	defer mx.RUnlock()
+
+	list := make([]*Kind, 0, len(kinds))
+	for _, kind := range kinds {
+		list = append(list, kind)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// Held reports whether sets hold blueprints of the kind.
+func (k *Kind) Held() bool {
+	return k.Loaded != nil
+}
+
+// DecodeBody decodes raw with the decoder of the named kind.
+func DecodeBody(name string, raw []byte) (registry.Request, error) {
+	kind, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return kind.DecodeBody(raw)
+}
+
+// ValidateDefinition checks def against the schema and validator of its kind.
+// It applies the same rules as the upload handlers.
+func (k *Kind) ValidateDefinition(def registry.Request) error {
+	if def == nil {
+		return fmt.Errorf("%s: missing definition", k.Name)
+	}
+
+	raw, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+
+	if err := k.ValidateBody(raw); err != nil {
+		return err
+	}
+
+	if k.Validate != nil {
+		return k.Validate(def)
+	}
+
+	return nil
+}
+
+// SaveDefinition validates def and saves it.
+func (k *Kind) SaveDefinition(ctx context.Context, version string, def registry.Request, force bool) error {
+	if err := k.ValidateDefinition(def); err != nil {
+		return err
+	}
+
+	return k.Save(ctx, version, def, force)
+}
+
+// Decoder builds a DecodeFunc that decodes the body into a T.
+func Decoder[T registry.Request]() DecodeFunc {
+	return func(raw []byte) (registry.Request, error) {
+		var def T
+		if err := json.Unmarshal(raw, &def); err != nil {
+			return nil, err
+		}
+
+		return def, nil
+	}
+}
+
+// Saver adapts a typed save function of the kit registry to a SaveFunc.
+func Saver[T registry.Request](fn func(context.Context, string, T, bool) error) SaveFunc {
+	return func(ctx context.Context, version string, def registry.Request, force bool) error {
+		typed, ok := def.(T)
+		if !ok {
+			return fmt.Errorf("unexpected definition %T", def)
+		}
+
+		return fn(ctx, version, typed, force)
+	}
+}
+
+// Lookup adapts a typed lookup function of the kit registry to a LookupFunc.
+func Lookup[T any](fn func(context.Context, string, string) (T, error)) LookupFunc {
+	return func(ctx context.Context, version, slug string) (any, error) {
+		bp, err := fn(ctx, version, slug)
+		if err != nil {
+			return nil, registryError(err)
+		}
+
+		return bp, nil
+	}
+}
+
+// Cached adapts a typed cache getter to a CachedFunc.
+func Cached[T any](fn func(context.Context, string) (T, bool)) CachedFunc {
+	return func(ctx context.Context, slug string) (any, bool) {
+		return fn(ctx, slug)
+	}
+}
+
+// Lister adapts a typed list function of the database to a ListFunc, converting
+// every item with convert.
+func Lister[T any, R registry.Request](fn func(context.Context, string) ([]T, error), convert func(T) R) ListFunc {
+	return func(ctx context.Context, version string) ([]registry.Request, error) {
+		items, err := fn(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+
+		defs := make([]registry.Request, 0, len(items))
+		for _, item := range items {
+			defs = append(defs, convert(item))
+		}
+
+		return defs, nil
+	}
+}
@@ -0,0 +1,112 @@
+package blueprint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unitRequest struct {
+	Name   string `json:"name"`
+	Slug   string `json:"slug"`
+	Health int    `json:"health"`
+}
+
+func (u unitRequest) GetName() string { return u.Name }
+func (u unitRequest) GetSlug() string { return u.Slug }
+
+func TestRegister(t *testing.T) {
+	saved := make(map[string]unitRequest)
+
+	Register(&Kind{
+		Name:     "unit",
+		Decode:   Decoder[unitRequest](),
+		Model:    unitRequest{},
+		Required: []string{"slug"},
+		Save: Saver(func(_ context.Context, version string, unit unitRequest, _ bool) error {
+			saved[version+"/"+unit.Slug] = unit
+			return nil
+		}),
+		Lookup: Lookup(func(_ context.Context, version, slug string) (unitRequest, error) {
+			return saved[version+"/"+slug], nil
+		}),
+	})
+
+	kind, err := Find("units")
+	require.NoError(t, err)
+	assert.Equal(t, "unit", kind.Name)
+
+	def, err := DecodeBody("unit", []byte(`{"name":"Archer","slug":"archer","health":10}`))
+	require.NoError(t, err)
+	require.NoError(t, kind.SaveDefinition(context.Background(), "1.0.0", def, false))
+	assert.ErrorAs(t, kind.SaveDefinition(context.Background(), "1.0.0", unitRequest{Name: "Nameless"}, false), &SchemaError{})
+
+	found, err := kind.Lookup(context.Background(), "1.0.0", "archer")
+	require.NoError(t, err)
+	assert.Equal(t, unitRequest{Name: "Archer", Slug: "archer", Health: 10}, found)
+
+	_, err = Get("technology")
+	assert.ErrorIs(t, err, ErrUnknownKind)
+
+	assert.Panics(t, func() {
+		Register(&Kind{
+			Name:   "units",
+			Decode: Decoder[unitRequest](),
+			Save:   Saver(registry.SaveResourceBlueprint),
+			Lookup: Lookup(registry.GetResourceBlueprint),
+		})
+	})
+
+	// Held kinds have to convert their blueprints back to requests.
+	assert.Panics(t, func() {
+		Register(&Kind{
+			Name:   "squad",
+			Decode: Decoder[unitRequest](),
+			Save:   Saver(registry.SaveResourceBlueprint),
+			Lookup: Lookup(registry.GetResourceBlueprint),
+			Loaded: Loaded[*proto.ResourceBlueprint]("squads"),
+			Read:   Reader(ListResources),
+			New:    func() Blueprint { return &proto.ResourceBlueprint{} },
+		})
+	})
+}
+
+func TestBuildingSchema(t *testing.T) {
+	kind, err := Get(KindBuilding)
+	require.NoError(t, err)
+
+	tests := []struct {
+		label       string
+		body        string
+		expectError bool
+	}{
+		{label: "valid", body: `{"name":"House","slug":"house","build_time":"1m30s","cost":[{"resource":"wood","amount":5}]}`},
+		{label: "null lists", body: `{"name":"House","slug":"house","build_time":"10s","cost":null,"production":null}`},
+		{label: "production", body: `{"name":"Mill","slug":"mill","build_time":"10s","production":[{"product":[{"resource":"flour","amount":1}],"production_time":"5s"}]}`},
+		{label: "missing slug", body: `{"name":"House","build_time":"10s"}`, expectError: true},
+		{label: "empty slug", body: `{"name":"House","slug":"","build_time":"10s"}`, expectError: true},
+		{label: "missing build time", body: `{"name":"House","slug":"house"}`, expectError: true},
+		{label: "invalid build time", body: `{"name":"House","slug":"house","build_time":"soon"}`, expectError: true},
+		{label: "missing production time", body: `{"name":"Mill","slug":"mill","build_time":"10s","production":[{}]}`, expectError: true},
+		{label: "negative amount", body: `{"name":"House","slug":"house","build_time":"10s","cost":[{"resource":"wood","amount":-1}]}`, expectError: true},
+		{label: "unknown field", body: `{"name":"House","slug":"house","build_time":"10s","colour":"red"}`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			err := kind.ValidateBody([]byte(tt.body))
+			if tt.expectError {
+				assert.ErrorAs(t, err, &SchemaError{})
+				return
+			}
+
+			assert.NoError(t, err)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
@@ -0,0 +1,92 @@
+package mockwriter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/database/mock"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+)
+
+type versioned interface {
+	GetID() string
+	GetVersion() string
+	GetSlug() string
+}
+
+// MockWriter deletes and replaces blueprints in the kit's mock database.
+type MockWriter struct {
+	mx *sync.Mutex
+}
+
+func New() *MockWriter {
+	return &MockWriter{mx: &sync.Mutex{}}
+}
+
+func (m *MockWriter) DeleteBuildingBlueprint(ctx context.Context, version, slug string) error {
+	return withStore(m, func(db *mock.Store) error {
+		return remove(&db.BuildingBlueprints, version, slug)
+	})
+}
+
+func (m *MockWriter) DeleteResourceBlueprint(ctx context.Context, version, slug string) error {
+	return withStore(m, func(db *mock.Store) error {
+		return remove(&db.ResourceBlueprints, version, slug)
+	})
+}
+
+func (m *MockWriter) ReplaceBuildingBlueprint(ctx context.Context, bp *proto.BuildingBlueprint) error {
+	return withStore(m, func(db *mock.Store) error {
+		replace(&db.BuildingBlueprints, bp)
+		return nil
+	})
+}
+
+func (m *MockWriter) ReplaceResourceBlueprint(ctx context.Context, bp *proto.ResourceBlueprint) error {
+	return withStore(m, func(db *mock.Store) error {
+		replace(&db.ResourceBlueprints, bp)
+		return nil
+	})
+}
+
+func withStore(m *MockWriter, fn func(db *mock.Store) error) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	db, err := mock.Get()
+	if err != nil {
+		return err
+	}
+
+	return fn(db)
+}
+
+func remove[T versioned](items *[]T, version, slug string) error {
+	kept := make([]T, 0, len(*items))
+
+	for _, item := range *items {
+		if item.GetVersion() != version || item.GetSlug() != slug {
+			kept = append(kept, item)
+		}
+	}
+
+	if len(kept) == len(*items) {
+		return blueprint.ErrNotFound
+	}
+
+	*items = kept
+
+	return nil
+}
+
+func replace[T versioned](items *[]T, bp T) {
+	for i, item := range *items {
+		if item.GetID() == bp.GetID() {
+			(*items)[i] = bp
+			return
+		}
+	}
+
+	*items = append(*items, bp)
+}
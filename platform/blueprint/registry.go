@@ -0,0 +1,130 @@
+package blueprint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUnsupported is returned by deletes and forced saves when the registry
+// store can't change the blueprints it holds.
+var ErrUnsupported = errors.New("registry store can't delete or replace blueprints")
+
+// mockNotFound is the error the kit's mock database fails with on missing
+// blueprints.
+const mockNotFound = "not found"
+
+// registryError turns the kit's report of a missing blueprint into
+// ErrNotFound. The cockroach backend reports missing rows, the mock database
+// a plain "not found". Any other error is returned as it is.
+func registryError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) || err.Error() == mockNotFound {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	return err
+}
+
+// Writer changes blueprints the kit's database.Store only adds. Deletes
+// return ErrNotFound or the store's report of a missing row if there is
+// nothing to delete, replaces write a blueprint over the one with its ID in a
+// single step, or add it.
+type Writer interface {
+	DeleteBuildingBlueprint(ctx context.Context, version, slug string) error
+	DeleteResourceBlueprint(ctx context.Context, version, slug string) error
+	ReplaceBuildingBlueprint(ctx context.Context, bp *proto.BuildingBlueprint) error
+	ReplaceResourceBlueprint(ctx context.Context, bp *proto.ResourceBlueprint) error
+}
+
+var (
+	writerMx = &sync.RWMutex{}
+	writer   Writer
+)
+
+// SetWriter selects the writer used with stores of the kit that don't
+// implement Writer themselves.
+func SetWriter(w Writer) {
+	writerMx.Lock()
+	defer writerMx.Unlock()
+
+	writer = w
+}
+
+// getWriter returns the kit's store if it implements Writer, the writer set
+// with SetWriter otherwise.
+func getWriter() (Writer, error) {
+	db, err := database.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if w, ok := db.(Writer); ok {
+		return w, nil
+	}
+
+	writerMx.RLock()
+	defer writerMx.RUnlock()
+
+	if writer == nil {
+		return nil, ErrUnsupported
+	}
+
+	return writer, nil
+}
+
+// Deleter adapts a delete method of Writer to a DeleteFunc.
+func Deleter(fn func(Writer, context.Context, string, string) error) DeleteFunc {
+	return func(ctx context.Context, version, slug string) error {
+		w, err := getWriter()
+		if err != nil {
+			return err
+		}
+
+		return registryError(fn(w, ctx, version, slug))
+	}
+}
+
+// SaveBuilding saves a building blueprint to the registry. Forced saves
+// replace the stored blueprint.
+func SaveBuilding(ctx context.Context, version string, def registry.BuildingBlueprintRequest, force bool) error {
+	if !force {
+		return registry.SaveBuildingBlueprint(ctx, version, def, false)
+	}
+
+	bp, err := BuildingProtoFromRequest(version, def)
+	if err != nil {
+		return err
+	}
+
+	w, err := getWriter()
+	if err != nil {
+		return err
+	}
+
+	return w.ReplaceBuildingBlueprint(ctx, bp)
+}
+
+// SaveResource saves a resource blueprint to the registry. Forced saves
+// replace the stored blueprint.
+func SaveResource(ctx context.Context, version string, def registry.ResourceBlueprintRequest, force bool) error {
+	if !force {
+		return registry.SaveResourceBlueprint(ctx, version, def, false)
+	}
+
+	w, err := getWriter()
+	if err != nil {
+		return err
+	}
+
+	return w.ReplaceResourceBlueprint(ctx, ResourceProtoFromRequest(version, def))
+}
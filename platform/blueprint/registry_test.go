@@ -0,0 +1,35 @@
+package blueprint
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryError(t *testing.T) {
+	tests := []struct {
+		label    string
+		err      error
+		notFound bool
+	}{
+		{label: "missing row", err: fmt.Errorf("scan: %w", pgx.ErrNoRows), notFound: true},
+		{label: "mock", err: errors.New("not found"), notFound: true},
+		{label: "connection", err: errors.New("dial tcp: connection refused")},
+		{label: "timeout", err: fmt.Errorf("query: %w", errors.New("not found in time"))},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			err := registryError(tt.err)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.notFound, errors.Is(err, ErrNotFound))
+		}
+
+		t.Run(tt.label, tf)
+	}
+
+	assert.NoError(t, registryError(nil))
+}
@@ -0,0 +1,252 @@
+package blueprint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const (
+	schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// durationPattern matches the strings accepted by time.ParseDuration.
+	durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|μs|ms|s|m|h))+)$`
+)
+
+// SchemaError is returned when a blueprint body doesn't match the schema of
+// its kind.
+type SchemaError struct {
+	Kind string
+	Err  error
+}
+
+func (e SchemaError) Error() string { return fmt.Sprintf("%s: %s", e.Kind, e.Err) }
+func (e SchemaError) Unwrap() error { return e.Err }
+
+// Schema is the JSON Schema of a blueprint document of this kind, with the
+// body described by the type of Model. It is nil for kinds without a model.
+func (k *Kind) Schema() ([]byte, error) {
+	k.schemaOnce.Do(k.buildSchema)
+
+	return k.schema, k.schemaErr
+}
+
+// ValidateBody checks the JSON body of a blueprint against the schema.
+func (k *Kind) ValidateBody(raw []byte) error {
+	k.schemaOnce.Do(k.buildSchema)
+
+	if k.schemaErr != nil {
+		return k.schemaErr
+	}
+
+	if k.compiled == nil {
+		return nil
+	}
+
+	var body any
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return err
+	}
+
+	if err := k.compiled.Validate(body); err != nil {
+		return SchemaError{Kind: k.Name, Err: err}
+	}
+
+	return nil
+}
+
+// DecodeBody validates raw against the schema of the kind and decodes it.
+func (k *Kind) DecodeBody(raw []byte) (registry.Request, error) {
+	if err := k.ValidateBody(raw); err != nil {
+		return nil, err
+	}
+
+	return k.Decode(raw)
+}
+
+func (k *Kind) buildSchema() {
+	if k.Model == nil {
+		return
+	}
+
+	durations := make(map[string]bool, len(k.Durations))
+	for _, field := range k.Durations {
+		durations[field] = true
+	}
+
+	body := typeSchema(reflect.TypeOf(k.Model), durations)
+	properties, _ := body["properties"].(map[string]any)
+
+	for _, field := range k.Required {
+		if property, ok := properties[field].(map[string]any); ok && property["type"] == "string" {
+			property["minLength"] = 1
+		}
+
+		body["required"] = appendRequired(body["required"], field)
+	}
+
+	// Blueprints extending another one only carry the fields they override,
+	// any of them may be left out.
+	overrides := make(map[string]any, len(body))
+	for key, value := range body {
+		if key != "required" {
+			overrides[key] = value
+		}
+	}
+
+	document := map[string]any{
+		"$schema": schemaDraft,
+		"title":   fmt.Sprintf("%s blueprint", k.Name),
+		"type":    "object",
+		"properties": map[string]any{
+			"kind":    map[string]any{"const": k.Name},
+			"version": map[string]any{"type": "string"},
+			"force":   map[string]any{"type": "boolean"},
+			"extends": map[string]any{"type": "string", "minLength": 1},
+			"body":    map[string]any{"type": "object"},
+			"locales": map[string]any{"$ref": "#/definitions/locales"},
+		},
+		"required":             []string{"kind"},
+		"additionalProperties": false,
+		"if":                   map[string]any{"required": []string{"extends"}},
+		"then": map[string]any{
+			"properties": map[string]any{"body": map[string]any{"$ref": "#/definitions/overrides"}},
+		},
+		"else": map[string]any{
+			"properties": map[string]any{"body": map[string]any{"$ref": "#/definitions/body"}},
+			"required":   []string{"body"},
+		},
+		"definitions": map[string]any{
+			"body":      body,
+			"overrides": overrides,
+			// Display text keyed by BCP 47 language tag.
+			"locales": map[string]any{
+				"type":                 "object",
+				"propertyNames":        map[string]any{"minLength": 1},
+				"additionalProperties": typeSchema(reflect.TypeOf(metadata.Display{}), nil),
+			},
+		},
+	}
+
+	raw, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		k.schemaErr = err
+		return
+	}
+
+	url := "urn:avalon:blueprint:" + k.Name
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+
+	if err := compiler.AddResource(url, bytes.NewReader(raw)); err != nil {
+		k.schemaErr = err
+		return
+	}
+
+	compiled, err := compiler.Compile(url + "#/definitions/body")
+	if err != nil {
+		k.schemaErr = err
+		return
+	}
+
+	k.schema = raw
+	k.compiled = compiled
+}
+
+// typeSchema describes t by its JSON encoding. String fields named in
+// durations are mandatory and must hold a Go duration.
+func typeSchema(t reflect.Type, durations map[string]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  []string{"array", "null"},
+			"items": typeSchema(t.Elem(), durations),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), durations),
+		}
+	case reflect.Struct:
+		var (
+			properties = make(map[string]any)
+			required   []string
+		)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name := jsonName(field)
+			if name == "-" {
+				continue
+			}
+
+			property := typeSchema(field.Type, durations)
+			if durations[name] && property["type"] == "string" {
+				property["pattern"] = durationPattern
+				required = append(required, name)
+			}
+
+			properties[name] = property
+		}
+
+		schema := map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+
+		return schema
+	default:
+		return map[string]any{}
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if tag == "" {
+		return field.Name
+	}
+
+	return tag
+}
+
+func appendRequired(current any, field string) []string {
+	required, _ := current.([]string)
+
+	for _, name := range required {
+		if name == field {
+			return required
+		}
+	}
+
+	return append(required, field)
+}
@@ -0,0 +1,199 @@
+package blueprint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// Blueprint is a blueprint as the cache holds it. Sets, snapshots and the
+// lookups on the message bus all carry blueprints in this shape.
+type Blueprint interface {
+	protobuf.Message
+	GetSlug() string
+}
+
+type (
+	// LoadedFunc returns the blueprints of the active version loaded in the
+	// cache.
+	LoadedFunc func(ctx context.Context) []Blueprint
+
+	// ReadFunc reads every blueprint of a version from the registry.
+	ReadFunc func(ctx context.Context, version string) ([]Blueprint, error)
+
+	// NewFunc returns an empty blueprint to decode into.
+	NewFunc func() Blueprint
+
+	// RequestFunc converts a held blueprint back to the request it is
+	// uploaded as.
+	RequestFunc func(bp Blueprint) (registry.Request, error)
+)
+
+// Set holds the blueprints of a version by kind, sorted by slug once Sort
+// was called.
+type Set map[string][]Blueprint
+
+// Add appends bps to the blueprints of kind.
+func (s Set) Add(kind string, bps ...Blueprint) {
+	s[kind] = append(s[kind], bps...)
+}
+
+func (s Set) Sort() {
+	for _, bps := range s {
+		sort.Slice(bps, func(i, j int) bool { return bps[i].GetSlug() < bps[j].GetSlug() })
+	}
+}
+
+// Len returns the number of blueprints of every kind.
+func (s Set) Len() int {
+	n := 0
+	for _, bps := range s {
+		n += len(bps)
+	}
+
+	return n
+}
+
+// Find looks up a blueprint of a sorted set.
+func (s Set) Find(kind, slug string) (Blueprint, bool) {
+	bps := s[kind]
+
+	i := sort.Search(len(bps), func(i int) bool { return bps[i].GetSlug() >= slug })
+	if i < len(bps) && bps[i].GetSlug() == slug {
+		return bps[i], true
+	}
+
+	return nil, false
+}
+
+// Loaded returns the set in the shape of the loaded blueprints of the cache,
+// by collection and slug.
+func (s Set) Loaded() map[string]any {
+	loaded := make(map[string]any, len(s))
+
+	for _, kind := range Held() {
+		bySlug := make(map[string]Blueprint, len(s[kind.Name]))
+		for _, bp := range s[kind.Name] {
+			bySlug[bp.GetSlug()] = bp
+		}
+
+		loaded[kind.Collection] = bySlug
+	}
+
+	return loaded
+}
+
+func (s Set) Buildings() []*proto.BuildingBlueprint {
+	return Of[*proto.BuildingBlueprint](s, KindBuilding)
+}
+
+func (s Set) Resources() []*proto.ResourceBlueprint {
+	return Of[*proto.ResourceBlueprint](s, KindResource)
+}
+
+// Of returns the blueprints of kind that are a T.
+func Of[T Blueprint](s Set, kind string) []T {
+	typed := make([]T, 0, len(s[kind]))
+
+	for _, bp := range s[kind] {
+		if t, ok := bp.(T); ok {
+			typed = append(typed, t)
+		}
+	}
+
+	return typed
+}
+
+// Held returns the kinds that sets hold.
+func Held() []*Kind {
+	held := make([]*Kind, 0)
+
+	for _, kind := range Kinds() {
+		if kind.Held() {
+			held = append(held, kind)
+		}
+	}
+
+	return held
+}
+
+// FromCache returns the set of the active version loaded in the cache.
+func FromCache(ctx context.Context) Set {
+	set := make(Set)
+
+	for _, kind := range Held() {
+		set.Add(kind.Name, kind.Loaded(ctx)...)
+	}
+
+	set.Sort()
+
+	return set
+}
+
+// ReadSet reads every blueprint of a version from the registry. Versions
+// without blueprints come back empty.
+func ReadSet(ctx context.Context, version string) (Set, error) {
+	set := make(Set)
+
+	for _, kind := range Held() {
+		bps, err := kind.Read(ctx, version)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", kind.Collection, err)
+		}
+
+		set.Add(kind.Name, bps...)
+	}
+
+	set.Sort()
+
+	return set, nil
+}
+
+// Loaded builds a LoadedFunc reading the collection of the kit's cache that
+// holds T.
+func Loaded[T Blueprint](collection string) LoadedFunc {
+	return func(ctx context.Context) []Blueprint {
+		items, _ := cache.GetLoadedBlueprints(ctx)[collection].(map[string]T)
+
+		bps := make([]Blueprint, 0, len(items))
+		for _, item := range items {
+			bps = append(bps, item)
+		}
+
+		return bps
+	}
+}
+
+// Reader adapts a typed list function of the database to a ReadFunc.
+func Reader[T Blueprint](fn func(context.Context, string) ([]T, error)) ReadFunc {
+	return func(ctx context.Context, version string) ([]Blueprint, error) {
+		items, err := fn(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+
+		bps := make([]Blueprint, 0, len(items))
+		for _, item := range items {
+			bps = append(bps, item)
+		}
+
+		return bps, nil
+	}
+}
+
+// Requester adapts a typed conversion of held blueprints to a RequestFunc.
+func Requester[T Blueprint, R registry.Request](convert func(T) R) RequestFunc {
+	return func(bp Blueprint) (registry.Request, error) {
+		typed, ok := bp.(T)
+		if !ok {
+			return nil, fmt.Errorf("unexpected blueprint %T", bp)
+		}
+
+		return convert(typed), nil
+	}
+}
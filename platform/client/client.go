@@ -0,0 +1,141 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const DefaultTimeout = 30 * time.Second
+
+var ErrNotFound = errors.New("not found")
+
+// Client talks to the blueprint registry endpoints of a gateway.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	tokens  oauth2.TokenSource
+}
+
+type Option func(*Client)
+
+// WithToken authenticates every request with a static bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.tokens = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token, TokenType: "Bearer"})
+	}
+}
+
+// WithClientCredentials fetches and refreshes tokens with the OAuth2 client
+// credentials flow.
+func WithClientCredentials(config clientcredentials.Config) Option {
+	return func(c *Client) {
+		c.tokens = config.TokenSource(context.Background())
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.http = client
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: DefaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// PushBatch uploads a batch through the batch endpoint.
+func (c *Client) PushBatch(ctx context.Context, batch *model.BlueprintBatchRequest) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/registry/blueprints", bytes.NewReader(body), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/json")
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
+
+// Export downloads a whole version.
+func (c *Client) Export(ctx context.Context, version string) (*model.BlueprintBatchRequest, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/registry/export/"+url.PathEscape(version), nil, func(r *http.Request) {
+		r.Header.Set("Accept", "application/json")
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var batch model.BlueprintBatchRequest
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("failed to decode export: %w", err)
+	}
+
+	return &batch, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, prepare func(*http.Request)) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if prepare != nil {
+		prepare(req)
+	}
+
+	if c.tokens != nil {
+		token, err := c.tokens.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token: %w", err)
+		}
+
+		token.SetAuthHeader(req)
+	}
+
+	return c.http.Do(req)
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth/claims"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/handler"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	database.SetKind(database.DriverMock)
+	metadata.SetKind(database.DriverMock)
+	audit.SetKind(database.DriverMock)
+
+	var authorization string
+
+	router := chi.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+
+			c := &claims.Claims{
+				Subject: "196176fd-6e54-49c2-9e49-eb81406c68d5",
+				Access: map[string]claims.Access{
+					"dev.avalon.cool": {Resource: "dev.avalon.cool", Roles: []string{"registry:write"}},
+				},
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auth.ClaimsContext, c)))
+		})
+	})
+	router.Post("/registry/blueprints", handler.AddBlueprintBatch())
+	router.Get("/registry/export/{version}", handler.ExportBlueprints())
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	c := New(server.URL, WithToken("secret"))
+
+	batch := &model.BlueprintBatchRequest{
+		Version: "1.2.3",
+		Blueprints: map[string][]registry.Request{
+			model.KindBuilding: {
+				registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"},
+			},
+			model.KindResource: {
+				registry.ResourceBlueprintRequest{Name: "Wood", Slug: "wood"},
+			},
+		},
+	}
+
+	require.NoError(t, c.PushBatch(context.Background(), batch))
+	assert.Equal(t, "Bearer secret", authorization)
+
+	exported, err := c.Export(context.Background(), "1.2.3")
+	require.NoError(t, err)
+
+	changes, err := model.Diff(batch, exported)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = c.Export(context.Background(), "9.9.9")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	invalid := &model.BlueprintBatchRequest{
+		Version: "1.2.3",
+		Blueprints: map[string][]registry.Request{
+			model.KindResource: {registry.ResourceBlueprintRequest{Name: "Stone"}},
+		},
+	}
+
+	err = c.PushBatch(context.Background(), invalid)
+	assert.ErrorContains(t, err, "400 Bad Request")
+}
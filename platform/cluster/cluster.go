@@ -0,0 +1,252 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
+	"github.com/GnarloqGames/genesis-avalon-kit/transport"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	SubjectReload = "gateway.registry.reload"
+
+	StatusOK    = "OK"
+	StatusError = "ERROR"
+
+	DefaultTimeout = 3 * time.Second
+)
+
+type ReloadRequest struct {
+	Version     string `json:"version"`
+	Channel     string `json:"channel,omitempty"`
+	RequestedBy string `json:"requested_by"`
+}
+
+type ReloadResult struct {
+	Instance string `json:"instance"`
+	Version  string `json:"version"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// LoadHook runs after the blueprint cache switched to version.
+type LoadHook func(ctx context.Context, version string)
+
+var (
+	hooksMx = &sync.Mutex{}
+	hooks   = make([]LoadHook, 0)
+
+	active atomic.Value
+
+	// reloadMx serializes reloads, the cache is shared by the whole process.
+	reloadMx = &sync.Mutex{}
+)
+
+// Active returns the blueprint version loaded in the cache. Unlike the
+// configured version it is safe to read while a reload switches it.
+func Active() string {
+	version, _ := active.Load().(string)
+
+	return version
+}
+
+// SetActive records the version loaded in the cache. Only startup and Reload
+// set it.
+func SetActive(version string) {
+	active.Store(version)
+}
+
+// OnLoad registers a hook that runs after every successful load of the cache.
+func OnLoad(hook LoadHook) {
+	hooksMx.Lock()
+	defer hooksMx.Unlock()
+
+	hooks = append(hooks, hook)
+}
+
+// Loaded runs the load hooks for version. Loads outside of Reload, like the
+// one at startup, call it directly.
+func Loaded(ctx context.Context, version string) {
+	hooksMx.Lock()
+	registered := append([]LoadHook(nil), hooks...)
+	hooksMx.Unlock()
+
+	for _, hook := range registered {
+		hook(ctx, version)
+	}
+}
+
+// Node is a single gateway instance listening for cluster-wide commands.
+type Node struct {
+	ID      string
+	Channel string
+
+	bus *transport.Connection
+	sub *nats.Subscription
+}
+
+// Join subscribes the instance to cluster-wide commands. Reload requests that
+// target a channel are only handled by nodes following that channel.
+func Join(bus *transport.Connection, channel string) (*Node, error) {
+	node := &Node{
+		ID:      instanceID(),
+		Channel: channel,
+		bus:     bus,
+	}
+
+	sub, err := bus.Subscribe(SubjectReload, node.handleReload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", SubjectReload, err)
+	}
+
+	node.sub = sub
+
+	slog.Info("joined gateway cluster", "instance", node.ID, "channel", channel, "subject", SubjectReload)
+
+	return node, nil
+}
+
+func (n *Node) Leave() error {
+	if n.sub == nil {
+		return nil
+	}
+
+	return n.sub.Unsubscribe()
+}
+
+func (n *Node) handleReload(msg *nats.Msg) {
+	var req ReloadRequest
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		slog.Error("failed to decode reload request", "error", err)
+		return
+	}
+
+	if req.Channel != "" && req.Channel != n.Channel {
+		return
+	}
+
+	result := ReloadResult{
+		Instance: n.ID,
+		Version:  req.Version,
+		Status:   StatusOK,
+	}
+
+	if err := n.Reload(context.Background(), req.Version); err != nil {
+		slog.Error("failed to reload cache", "error", err, "version", req.Version, "requested_by", req.RequestedBy)
+
+		result.Status = StatusError
+		result.Error = err.Error()
+		result.Version = Active()
+	} else {
+		slog.Info("reloaded blueprint cache", "version", req.Version, "requested_by", req.RequestedBy)
+	}
+
+	if msg.Reply == "" {
+		return
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		slog.Error("failed to encode reload result", "error", err)
+		return
+	}
+
+	if err := msg.Respond(raw); err != nil {
+		slog.Error("failed to respond to reload request", "error", err)
+	}
+}
+
+// Reload switches the local blueprint cache to the given version. The previous
+// version is kept if the new one can't be loaded.
+func (n *Node) Reload(ctx context.Context, version string) error {
+	reloadMx.Lock()
+	defer reloadMx.Unlock()
+
+	previous := Active()
+
+	cache.SetVersion(version)
+
+	if err := cache.Load(ctx); err != nil {
+		cache.SetVersion(previous)
+		return err
+	}
+
+	SetActive(version)
+
+	Loaded(ctx, version)
+
+	return nil
+}
+
+// Reload broadcasts a reload request to every gateway instance and collects
+// the results that arrive before the timeout expires.
+func Reload(ctx context.Context, bus *transport.Connection, req ReloadRequest, timeout time.Duration) ([]ReloadResult, error) {
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := bus.NewRespInbox()
+
+	replies := make(chan *nats.Msg, 64)
+
+	sub, err := bus.ChanSubscribe(inbox, replies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to reply inbox: %w", err)
+	}
+	defer sub.Unsubscribe() //nolint
+
+	if err := bus.PublishRequest(SubjectReload, inbox, raw); err != nil {
+		return nil, fmt.Errorf("failed to publish reload request: %w", err)
+	}
+
+	return collect(ctx, replies, timeout), nil
+}
+
+func collect(ctx context.Context, replies <-chan *nats.Msg, timeout time.Duration) []ReloadResult {
+	results := make([]ReloadResult, 0)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case msg := <-replies:
+			var result ReloadResult
+			if err := json.Unmarshal(msg.Data, &result); err != nil {
+				slog.Error("failed to decode reload result", "error", err)
+				continue
+			}
+
+			results = append(results, result)
+		case <-timer.C:
+			return sortResults(results)
+		case <-ctx.Done():
+			return sortResults(results)
+		}
+	}
+}
+
+func sortResults(results []ReloadResult) []ReloadResult {
+	sort.Slice(results, func(i, j int) bool { return results[i].Instance < results[j].Instance })
+
+	return results
+}
+
+func instanceID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+	}
+
+	return uuid.NewString()
+}
@@ -0,0 +1,118 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/transport"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true})
+	require.NoError(t, err)
+
+	go ns.Start()
+
+	require.True(t, ns.ReadyForConnections(5*time.Second))
+
+	return ns
+}
+
+func connect(t *testing.T, ns *server.Server) *transport.Connection {
+	t.Helper()
+
+	cfg := transport.DefaultConfig
+	cfg.URL = ns.ClientURL()
+
+	bus, err := transport.NewConn(cfg)
+	require.NoError(t, err)
+
+	return bus
+}
+
+func TestReload(t *testing.T) {
+	database.SetKind(database.DriverMock)
+
+	db, _ := database.Get() //nolint
+	require.NoError(t, db.SaveBuildingBlueprint(context.Background(), &proto.BuildingBlueprint{Slug: "house", Version: "1.1.0"}))
+	require.NoError(t, db.SaveResourceBlueprint(context.Background(), &proto.ResourceBlueprint{Slug: "wood", Version: "1.1.0"}))
+
+	ns := startServer(t)
+	defer ns.Shutdown()
+
+	nodes := make([]*Node, 0)
+
+	for _, channel := range []string{"", "staging"} {
+		bus := connect(t, ns)
+		defer bus.Close()
+
+		node, err := Join(bus, channel)
+		require.NoError(t, err)
+
+		nodes = append(nodes, node)
+	}
+
+	admin := connect(t, ns)
+	defer admin.Close()
+
+	require.NoError(t, admin.Flush())
+
+	tests := []struct {
+		label           string
+		version         string
+		channel         string
+		expectedStatus  string
+		expectedResults int
+	}{
+		{
+			label:           "success",
+			version:         "1.1.0",
+			expectedStatus:  StatusOK,
+			expectedResults: 2,
+		},
+		{
+			label:           "missing-version",
+			version:         "9.9.9",
+			expectedStatus:  StatusError,
+			expectedResults: 2,
+		},
+		{
+			label:           "channel",
+			version:         "1.1.0",
+			channel:         "staging",
+			expectedStatus:  StatusOK,
+			expectedResults: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			req := ReloadRequest{Version: tt.version, Channel: tt.channel}
+
+			results, err := Reload(context.Background(), admin, req, 500*time.Millisecond)
+			require.NoError(t, err)
+			require.Len(t, results, tt.expectedResults)
+
+			for _, result := range results {
+				assert.Equal(t, tt.expectedStatus, result.Status)
+				assert.Equal(t, "1.1.0", result.Version)
+			}
+
+			assert.Equal(t, "1.1.0", Active())
+		}
+
+		t.Run(tt.label, tf)
+	}
+
+	for _, node := range nodes {
+		assert.NoError(t, node.Leave())
+	}
+}
@@ -0,0 +1,98 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/viper"
+)
+
+const defaultPort uint16 = 26257
+
+var (
+	ErrEmptyHostname = fmt.Errorf("hostname cannot be empty")
+	ErrEmptyDatabase = fmt.Errorf("database cannot be empty")
+)
+
+var (
+	pool *pgxpool.Pool
+	mx   = &sync.Mutex{}
+)
+
+// Pool returns the gateway's own connection pool to the registry database,
+// configured with the same settings as the kit's cockroach backend.
+func Pool(ctx context.Context) (*pgxpool.Pool, error) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	if pool != nil {
+		return pool, nil
+	}
+
+	dsn, err := connectionString()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	pool = p
+
+	return pool, nil
+}
+
+func Close() {
+	mx.Lock()
+	defer mx.Unlock()
+
+	if pool != nil {
+		pool.Close()
+		pool = nil
+	}
+}
+
+func connectionString() (string, error) {
+	hostname := viper.GetString(config.FlagDatabaseHost)
+	if hostname == "" {
+		return "", ErrEmptyHostname
+	}
+
+	database := viper.GetString(config.FlagDatabaseName)
+	if database == "" {
+		return "", ErrEmptyDatabase
+	}
+
+	port := viper.GetUint16(config.FlagDatabasePort)
+	if port == 0 {
+		port = defaultPort
+	}
+
+	dsn := url.URL{
+		Scheme: "postgresql",
+		Host:   net.JoinHostPort(hostname, strconv.Itoa(int(port))),
+		Path:   "/" + database,
+	}
+
+	if sslmode := viper.GetString(config.FlagDatabaseSSLMode); sslmode != "" {
+		dsn.RawQuery = url.Values{"sslmode": {sslmode}}.Encode()
+	}
+
+	if username := viper.GetString(config.FlagDatabaseUsername); username != "" {
+		if password := viper.GetString(config.FlagDatabasePassword); password != "" {
+			dsn.User = url.UserPassword(username, password)
+		} else {
+			dsn.User = url.User(username)
+		}
+	}
+
+	return dsn.String(), nil
+}
@@ -0,0 +1,93 @@
+package cockroach
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	buildingBlueprintsTable = "building_blueprints"
+	resourceBlueprintsTable = "resource_blueprints"
+)
+
+// Registry deletes and replaces blueprints in the tables of the kit's
+// cockroach backend, which only ever adds to them. Rows are written the way
+// the kit writes them.
+type Registry struct{}
+
+func (Registry) DeleteBuildingBlueprint(ctx context.Context, version, slug string) error {
+	return deleteBlueprint(ctx, buildingBlueprintsTable, version, slug)
+}
+
+func (Registry) DeleteResourceBlueprint(ctx context.Context, version, slug string) error {
+	return deleteBlueprint(ctx, resourceBlueprintsTable, version, slug)
+}
+
+func (Registry) ReplaceBuildingBlueprint(ctx context.Context, bp *proto.BuildingBlueprint) error {
+	return upsertBlueprint(ctx, buildingBlueprintsTable, bp.GetID(), bp.GetVersion(), bp.GetName(), bp.GetSlug(), bp)
+}
+
+func (Registry) ReplaceResourceBlueprint(ctx context.Context, bp *proto.ResourceBlueprint) error {
+	return upsertBlueprint(ctx, resourceBlueprintsTable, bp.GetID(), bp.GetVersion(), bp.GetName(), bp.GetSlug(), bp)
+}
+
+// deleteBlueprint removes a blueprint, reporting pgx.ErrNoRows like the kit
+// does on reads if there was none.
+func deleteBlueprint(ctx context.Context, table, version, slug string) error {
+	pool, err := Pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query, params, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete(table).
+		Where(sq.Eq{"version": version, "slug": slug}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := pool.Exec(ctx, query, params...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// upsertBlueprint writes a blueprint over the row with its ID in a single
+// statement, so a failed replace keeps the previous definition.
+func upsertBlueprint(ctx context.Context, table, id, version, name, slug string, definition any) error {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(definition); err != nil {
+		return err
+	}
+
+	pool, err := Pool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query, params, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert(table).
+		Columns("id", "version", "name", "slug", "definition").
+		Values(id, version, name, slug, buf.String()).
+		Suffix("ON CONFLICT (id) DO UPDATE SET version = excluded.version, name = excluded.name, slug = excluded.slug, definition = excluded.definition").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = pool.Exec(ctx, query, params...)
+
+	return err
+}
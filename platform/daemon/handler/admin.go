@@ -1,47 +1,138 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"strings"
+	"time"
 
-	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth"
-	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth/claims"
-	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
-	"github.com/go-chi/chi/v5"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/webhook"
+	"github.com/GnarloqGames/genesis-avalon-kit/transport"
 	"github.com/go-chi/render"
 )
 
-func ReloadBlueprints() http.HandlerFunc {
-	logger := slog.Default().With("context", "Build")
+const (
+	reloadStatusOK      = "OK"
+	reloadStatusPartial = "PARTIAL"
+	reloadStatusFailed  = "FAILED"
+)
+
+func ReloadBlueprints(bus *transport.Connection) http.HandlerFunc {
+	logger := slog.Default().With("context", "ReloadBlueprints")
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		claims, ok := r.Context().Value(auth.ClaimsContext).(*claims.Claims)
-		if !ok || claims == nil {
-			logger.Error("failed to read claims from context")
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		claims, ok := authorize(w, r, logger, "dev.avalon.cool:cache:reload")
+		if !ok {
+			return
+		}
 
+		version, ok := routeVersion(w, r)
+		if !ok {
 			return
 		}
 
-		if !claims.HasRole("dev.avalon.cool:cache:reload") {
-			logger.Error("user doesn't have correct permissions", "role", "dev.avalon.cool:can-build", "user_id", claims.Subject)
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		timeout := cluster.DefaultTimeout
+		if raw := r.URL.Query().Get("timeout"); raw != "" {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil || parsed <= 0 {
+				logger.Debug("invalid timeout", "timeout", raw)
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+				return
+			}
+
+			timeout = parsed
+		}
+
+		req := cluster.ReloadRequest{
+			Version:     version,
+			RequestedBy: claims.Subject,
+		}
+
+		results, err := cluster.Reload(r.Context(), bus, req, timeout)
+		if err != nil {
+			logger.Error("failed to broadcast reload request", "error", err, "version", version)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 
 			return
 		}
 
-		version := strings.TrimPrefix(chi.URLParam(r, "version"), "v")
+		status := reportReload(r.Context(), claims.Subject, version, results)
 
-		cache.SetVersion(version)
+		render.JSON(w, r, map[string]any{
+			"status":    status,
+			"version":   version,
+			"instances": results,
+		})
+	}
 
-		if err := cache.Load(r.Context()); err != nil {
-			logger.Error("failed to reload cache", "error", err, "version", version)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	return fn
+}
+
+// reportReload records a reload in the audit trail and announces the version
+// to the webhooks, unless no instance loaded it. It returns the status of the
+// reload.
+func reportReload(ctx context.Context, subject, version string, results []cluster.ReloadResult) string {
+	status := reloadStatus(results)
+
+	if err := auditReload(ctx, subject, version, status); err != nil {
+		slog.Error("failed to audit reload", "error", err, "version", version)
+	}
 
+	if status != reloadStatusFailed {
+		event := webhook.NewEvent(webhook.EventVersionActivated, version)
+		event.Subject = subject
+		event.Status = status
+		webhook.Publish(event)
+	}
+
+	return status
+}
+
+func reloadStatus(results []cluster.ReloadResult) string {
+	failed := 0
+
+	for _, result := range results {
+		if result.Status != cluster.StatusOK {
+			failed++
+		}
+	}
+
+	switch {
+	case len(results) == 0, failed == len(results):
+		return reloadStatusFailed
+	case failed > 0:
+		return reloadStatusPartial
+	default:
+		return reloadStatusOK
+	}
+}
+
+// ListWebhookDeliveries returns the latest webhook deliveries, newest first,
+// optionally only those with the status given in the status parameter.
+func ListWebhookDeliveries() http.HandlerFunc {
+	logger := slog.Default().With("context", "ListWebhookDeliveries")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := authorize(w, r, logger, "dev.avalon.cool:webhooks:read"); !ok {
 			return
 		}
 
-		render.JSON(w, r, map[string]string{"status": "OK"})
+		status := r.URL.Query().Get("status")
+
+		switch status {
+		case "", webhook.StatusPending, webhook.StatusDelivered, webhook.StatusFailed:
+		default:
+			http.Error(w, fmt.Sprintf("invalid status: %s", status), http.StatusBadRequest)
+			return
+		}
+
+		deliveries := make([]webhook.Delivery, 0)
+		if d := webhook.Default(); d != nil {
+			deliveries = d.Log().List(status)
+		}
+
+		respond(w, r, deliveries)
 	}
 
 	return fn
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookVersion = "19.0.0"
+
+func TestWebhookDeliveries(t *testing.T) {
+	setupStores(t)
+
+	var (
+		mx       = &sync.Mutex{}
+		received []webhook.Event
+	)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhook.Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		mx.Lock()
+		received = append(received, event)
+		mx.Unlock()
+	}))
+	t.Cleanup(receiver.Close)
+
+	d := webhook.NewDispatcher([]webhook.Subscription{{URL: receiver.URL, Secret: "secret"}})
+	d.RetryMin = time.Millisecond
+
+	webhook.SetDispatcher(d)
+	t.Cleanup(func() {
+		webhook.SetDispatcher(nil)
+	})
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Get("/registry/webhooks/deliveries", ListWebhookDeliveries())
+
+	req := httptest.NewRequest(http.MethodPost, "/registry/blueprints", bytes.NewReader([]byte(`{"version": "`+webhookVersion+`",
+		"buildings": [{"name": "House", "slug": "house", "build_time": "10s"}],
+		"resources": [{"name": "Wood", "slug": "wood"}]}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req = withClaims(req, "registry:write")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	d.Wait()
+
+	require.Len(t, received, 1)
+	assert.Equal(t, webhook.EventVersionWritten, received[0].Type)
+	assert.Equal(t, webhookVersion, received[0].Version)
+	assert.Equal(t, 2, received[0].Count)
+
+	list := func(query string, roles ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/registry/webhooks/deliveries"+query, nil)
+		req = withClaims(req, roles...)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, list("").Code)
+	assert.Equal(t, http.StatusBadRequest, list("?status=lost", "webhooks:read").Code)
+
+	rec = list("?status=delivered", "webhooks:read")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var deliveries []webhook.Delivery
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &deliveries))
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, receiver.URL, deliveries[0].URL)
+	assert.Equal(t, received[0].ID, deliveries[0].Event.ID)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+
+	rec = list("?status=failed", "webhooks:read")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[]`, rec.Body.String())
+}
+
+func TestReportReload(t *testing.T) {
+	setupStores(t)
+
+	var (
+		mx       = &sync.Mutex{}
+		received []webhook.Event
+	)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhook.Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		mx.Lock()
+		received = append(received, event)
+		mx.Unlock()
+	}))
+	t.Cleanup(receiver.Close)
+
+	d := webhook.NewDispatcher([]webhook.Subscription{{URL: receiver.URL}})
+	d.RetryMin = time.Millisecond
+
+	webhook.SetDispatcher(d)
+	t.Cleanup(func() {
+		webhook.SetDispatcher(nil)
+	})
+
+	ctx := context.Background()
+
+	ok := []cluster.ReloadResult{{Instance: "a", Version: "19.1.0", Status: cluster.StatusOK}}
+	assert.Equal(t, reloadStatusOK, reportReload(ctx, "promoter", "19.1.0", ok))
+	assert.Equal(t, reloadStatusFailed, reportReload(ctx, "promoter", "19.2.0", nil))
+
+	d.Wait()
+
+	require.Len(t, received, 1)
+	assert.Equal(t, webhook.EventVersionActivated, received[0].Type)
+	assert.Equal(t, "19.1.0", received[0].Version)
+	assert.Equal(t, "promoter", received[0].Subject)
+
+	store, err := audit.Get()
+	require.NoError(t, err)
+
+	records, err := store.List(ctx, audit.Filter{Subject: "promoter", Action: audit.ActionReload})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, reloadStatusFailed, records[0].Status)
+	assert.Equal(t, reloadStatusOK, records[1].Status)
+}
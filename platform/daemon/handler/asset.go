@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/asset"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/registrar"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// maxAssetSize is the largest asset that can be uploaded.
+const maxAssetSize = 16 << 20
+
+type assetResponse struct {
+	*asset.Asset
+	URL string `json:"url"`
+}
+
+// assetURL is where an asset is downloaded from. The hash makes the URL change
+// with the content, so responses to it can be cached for good.
+func assetURL(a *asset.Asset) string {
+	return fmt.Sprintf("/registry/blueprint/%s/%s/%s/assets/%s?hash=%s", a.Version, a.Kind, a.Slug, a.Name, a.Hash)
+}
+
+// blueprintAssets returns the URLs of the assets of a blueprint by name, or
+// nil if it has none or asset hosting is disabled.
+func blueprintAssets(ctx context.Context, version, kind, slug string) (map[string]string, error) {
+	store, err := asset.Get()
+	if errors.Is(err, asset.ErrDisabled) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := store.List(ctx, version, kind, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(assets) == 0 {
+		return nil, nil
+	}
+
+	urls := make(map[string]string, len(assets))
+	for _, a := range assets {
+		urls[a.Name] = assetURL(a)
+	}
+
+	return urls, nil
+}
+
+// UploadAsset stores a file attached to a blueprint, such as its icon, under
+// a name. Uploading to the same name replaces the asset.
+func UploadAsset() http.HandlerFunc {
+	logger := slog.Default().With("context", "UploadAsset")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authorize(w, r, logger, "dev.avalon.cool:registry:assets")
+		if !ok {
+			return
+		}
+
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+		slug := chi.URLParam(r, "slug")
+		name := chi.URLParam(r, "name")
+
+		kind, err := blueprint.Get(chi.URLParam(r, "kind"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		store, err := asset.Get()
+		if err != nil {
+			if errors.Is(err, asset.ErrDisabled) {
+				http.Error(w, err.Error(), http.StatusNotImplemented)
+			} else {
+				logger.Error("failed to get asset store", "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err := registrar.CheckWritable(r.Context(), version); err != nil {
+			logger.Debug("version is not writable", "error", err, "version", version)
+			writeVersionError(w, err)
+
+			return
+		}
+
+		exists, err := blueprintExists(r.Context(), version, kind.Name, slug)
+		if err != nil {
+			logger.Error("failed to look up blueprint", "error", err, "version", version, "kind", kind.Name, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if !exists {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		body := bufio.NewReader(http.MaxBytesReader(w, r.Body, maxAssetSize))
+
+		// Uploads without a useful content type are sniffed, so assets are
+		// always served with one.
+		contentType := r.Header.Get("Content-Type")
+		if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || mediaType == "application/octet-stream" {
+			head, _ := body.Peek(512)
+			contentType = http.DetectContentType(head)
+		}
+
+		a, err := asset.New(version, kind.Name, slug, name, contentType)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := store.Put(r.Context(), a, body); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			logger.Error("failed to store asset", "error", err, "version", version, "kind", kind.Name, "slug", slug, "name", name)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		logger.Info("uploaded asset", "version", version, "kind", kind.Name, "slug", slug, "name", name, "hash", a.Hash, "user_id", claims.Subject)
+
+		respond(w, r, assetResponse{Asset: a, URL: assetURL(a)})
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// GetAsset serves an asset with its content hash as ETag. Requests for the
+// current hash are cached for good, others revalidate.
+func GetAsset() http.HandlerFunc {
+	logger := slog.Default().With("context", "GetAsset")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+		slug := chi.URLParam(r, "slug")
+		name := chi.URLParam(r, "name")
+
+		kind, err := blueprint.Get(chi.URLParam(r, "kind"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		store, err := asset.Get()
+		if err != nil {
+			if errors.Is(err, asset.ErrDisabled) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				logger.Error("failed to get asset store", "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		a, content, err := store.Get(r.Context(), version, kind.Name, slug, name)
+		if err != nil {
+			if errors.Is(err, asset.ErrNotFound) || errors.Is(err, asset.ErrInvalidName) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				logger.Error("failed to get asset", "error", err, "version", version, "kind", kind.Name, "slug", slug, "name", name)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		defer content.Close()
+
+		header := w.Header()
+		header.Set("Content-Type", a.ContentType)
+		header.Set("ETag", a.ETag())
+		header.Set("X-Content-Type-Options", "nosniff")
+
+		if r.URL.Query().Get("hash") == a.Hash {
+			header.Set("Cache-Control", cacheControlImmutable)
+		} else {
+			header.Set("Cache-Control", cacheControlRevalidated)
+		}
+
+		http.ServeContent(w, r, a.Name, a.UpdatedAt, content)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// ListAssets returns the assets of a blueprint.
+func ListAssets() http.HandlerFunc {
+	logger := slog.Default().With("context", "ListAssets")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+		slug := chi.URLParam(r, "slug")
+
+		kind, err := blueprint.Get(chi.URLParam(r, "kind"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		response := make([]assetResponse, 0)
+
+		store, err := asset.Get()
+		if errors.Is(err, asset.ErrDisabled) {
+			respond(w, r, response)
+			return
+		}
+
+		if err != nil {
+			logger.Error("failed to get asset store", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		assets, err := store.List(r.Context(), version, kind.Name, slug)
+		if err != nil {
+			if errors.Is(err, asset.ErrInvalidName) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				logger.Error("failed to list assets", "error", err, "version", version, "kind", kind.Name, "slug", slug)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		for _, a := range assets {
+			response = append(response, assetResponse{Asset: a, URL: assetURL(a)})
+		}
+
+		respond(w, r, response)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+func DeleteAsset() http.HandlerFunc {
+	logger := slog.Default().With("context", "DeleteAsset")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authorize(w, r, logger, "dev.avalon.cool:registry:assets")
+		if !ok {
+			return
+		}
+
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+		slug := chi.URLParam(r, "slug")
+		name := chi.URLParam(r, "name")
+
+		kind, err := blueprint.Get(chi.URLParam(r, "kind"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		store, err := asset.Get()
+		if err != nil {
+			if errors.Is(err, asset.ErrDisabled) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				logger.Error("failed to get asset store", "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err := registrar.CheckWritable(r.Context(), version); err != nil {
+			logger.Debug("version is not writable", "error", err, "version", version)
+			writeVersionError(w, err)
+
+			return
+		}
+
+		if err := store.Delete(r.Context(), version, kind.Name, slug, name); err != nil {
+			if errors.Is(err, asset.ErrNotFound) || errors.Is(err, asset.ErrInvalidName) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				logger.Error("failed to delete asset", "error", err, "version", version, "kind", kind.Name, "slug", slug, "name", name)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		logger.Info("deleted asset", "version", version, "kind", kind.Name, "slug", slug, "name", name, "user_id", claims.Subject)
+
+		render.JSON(w, r, map[string]string{"status": "OK"})
+	}
+
+	return http.HandlerFunc(fn)
+}
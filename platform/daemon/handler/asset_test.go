@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/asset"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const assetVersion = "18.0.0"
+
+func TestBlueprintAssets(t *testing.T) {
+	setupStores(t)
+	asset.SetKind(asset.KindMemory)
+
+	t.Cleanup(func() {
+		asset.SetKind(asset.KindFilesystem)
+	})
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}", GetBlueprint())
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}/assets", ListAssets())
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}/assets/{name}", GetAsset())
+	router.Put("/registry/blueprint/{version}/{kind}/{slug}/assets/{name}", UploadAsset())
+	router.Delete("/registry/blueprint/{version}/{kind}/{slug}/assets/{name}", DeleteAsset())
+
+	do := func(method, path string, body []byte, headers map[string]string, roles ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		req = withClaims(req, roles...)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/registry/blueprints", []byte(`{"version": "`+assetVersion+`",
+		"buildings": [{"name": "House", "slug": "house", "build_time": "10s"}]}`), map[string]string{"Content-Type": "application/json"}, "registry:write")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	base := "/registry/blueprint/" + assetVersion + "/building/"
+	icon := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPut, base+"house/assets/icon", icon, nil).Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPut, base+"hut/assets/icon", icon, nil, "registry:assets").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPut, base+"house/assets/.icon", icon, nil, "registry:assets").Code)
+
+	rec = do(http.MethodPut, base+"house/assets/icon", icon, nil, "registry:assets")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var uploaded struct {
+		ContentType string `json:"content_type"`
+		Hash        string `json:"hash"`
+		Size        int64  `json:"size"`
+		URL         string `json:"url"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &uploaded))
+	assert.Equal(t, "image/png", uploaded.ContentType)
+	assert.Equal(t, int64(len(icon)), uploaded.Size)
+	assert.Equal(t, base+"house/assets/icon?hash="+uploaded.Hash, uploaded.URL)
+
+	rec = do(http.MethodGet, uploaded.URL, nil, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, icon, rec.Body.Bytes())
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `"`+uploaded.Hash+`"`, rec.Header().Get("ETag"))
+	assert.Equal(t, cacheControlImmutable, rec.Header().Get("Cache-Control"))
+
+	rec = do(http.MethodGet, base+"house/assets/icon", nil, map[string]string{"If-None-Match": `"` + uploaded.Hash + `"`})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, cacheControlRevalidated, rec.Header().Get("Cache-Control"))
+
+	rec = do(http.MethodGet, base+"house", nil, map[string]string{"Accept": "application/json"})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var bp struct {
+		Assets map[string]string `json:"assets"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &bp))
+	assert.Equal(t, map[string]string{"icon": uploaded.URL}, bp.Assets)
+
+	rec = do(http.MethodGet, base+"house/assets", nil, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), uploaded.URL)
+
+	rec = do(http.MethodPut, base+"house/assets/icon", []byte("<svg></svg>"), map[string]string{"Content-Type": "image/svg+xml"}, "registry:assets")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), uploaded.Hash)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, uploaded.URL, nil, nil).Code)
+	assert.Equal(t, cacheControlRevalidated, do(http.MethodGet, uploaded.URL, nil, nil).Header().Get("Cache-Control"))
+
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, base+"house/assets/icon", nil, nil, "registry:assets").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, base+"house/assets/icon", nil, nil, "registry:assets").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, base+"house/assets/icon", nil, nil).Code)
+
+	rec = do(http.MethodGet, base+"house", nil, map[string]string{"Accept": "application/json"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "assets")
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+)
+
+// auditReload records a reload of version and its status.
+func auditReload(ctx context.Context, subject, version, status string) error {
+	store, err := audit.Get()
+	if err != nil {
+		return err
+	}
+
+	record := audit.NewRecord(audit.ActionReload, subject, version)
+	record.Status = status
+
+	return store.Append(ctx, record)
+}
+
+func parseAuditFilter(values url.Values) (audit.Filter, error) {
+	filter := audit.Filter{
+		Subject: values.Get("subject"),
+		Version: strings.TrimPrefix(values.Get("version"), "v"),
+		Kind:    values.Get("kind"),
+		Slug:    values.Get("slug"),
+		Action:  values.Get("action"),
+	}
+
+	switch filter.Action {
+	case "", audit.ActionSave, audit.ActionReload:
+	default:
+		return filter, fmt.Errorf("invalid action: %s", filter.Action)
+	}
+
+	var err error
+
+	if raw := values.Get("since"); raw != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, raw); err != nil {
+			return filter, fmt.Errorf("since: %w", err)
+		}
+	}
+
+	if raw := values.Get("until"); raw != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, raw); err != nil {
+			return filter, fmt.Errorf("until: %w", err)
+		}
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil || filter.Limit < 1 {
+			return filter, fmt.Errorf("invalid limit: %s", raw)
+		}
+	}
+
+	return filter, nil
+}
+
+// ListAudit returns the audit trail newest first, filtered by the subject,
+// version, kind, slug, action, since and until parameters.
+func ListAudit() http.HandlerFunc {
+	logger := slog.Default().With("context", "ListAudit")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := authorize(w, r, logger, "dev.avalon.cool:registry:audit"); !ok {
+			return
+		}
+
+		filter, err := parseAuditFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		store, err := audit.Get()
+		if err != nil {
+			logger.Error("failed to get audit store", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		records, err := store.List(r.Context(), filter)
+		if err != nil {
+			logger.Error("failed to list audit records", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		respond(w, r, records)
+	}
+
+	return http.HandlerFunc(fn)
+}
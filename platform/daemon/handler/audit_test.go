@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditTrail(t *testing.T) {
+	setupStores(t)
+
+	const version = "22.0.0"
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprint", AddBlueprint())
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Get("/registry/audit", ListAudit())
+
+	upload := func(path, body string) {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req = withClaims(req, "registry:write")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	list := func(query string, roles ...string) (int, []audit.Record) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, withClaims(httptest.NewRequest(http.MethodGet, "/registry/audit?"+query, nil), roles...))
+
+		var records []audit.Record
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &records))
+		}
+
+		return rec.Code, records
+	}
+
+	anonymous := httptest.NewRequest(http.MethodPost, "/registry/blueprint", bytes.NewReader([]byte(`{"kind":"building","version":"`+version+`","body":{"name":"Shed","slug":"shed","build_time":"10s"}}`)))
+	anonymous.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, anonymous)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	upload("/registry/blueprint", `{"kind":"building","version":"`+version+`","body":{"name":"House","slug":"house","build_time":"10s"}}`)
+	upload("/registry/blueprints", `{"version":"`+version+`","buildings":[{"name":"Big House","slug":"house","build_time":"10000ms"}],"resources":[{"name":"Wood","slug":"wood"}]}`)
+
+	code, _ := list("version="+version, "registry:assets")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = list("since=yesterday", "registry:audit")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, records := list("version="+version+"&kind=building", "registry:audit")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, records, 2)
+
+	latest, first := records[0], records[1]
+
+	for _, r := range records {
+		assert.Equal(t, audit.ActionSave, r.Action)
+		assert.Equal(t, "196176fd-6e54-49c2-9e49-eb81406c68d5", r.Subject)
+		assert.Equal(t, "house", r.Slug)
+		assert.Len(t, r.Hash, 64)
+	}
+
+	assert.NotEqual(t, first.Hash, latest.Hash)
+	assert.Contains(t, first.Diff, audit.Change{Path: "name", After: "House"})
+
+	// The build time only changed its notation, so it isn't part of the diff.
+	assert.Equal(t, []audit.Change{{Path: "name", Before: "House", After: "Big House"}}, latest.Diff)
+
+	code, records = list("version="+version+"&slug=wood", "registry:audit")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, records, 1)
+	assert.Equal(t, "resource", records[0].Kind)
+
+	code, records = list("version="+version+"&slug=shed", "registry:audit")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, records)
+}
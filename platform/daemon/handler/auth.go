@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth/claims"
+)
+
+// authorize reads the claims injected by the auth middleware and checks that
+// the user has the role. The error response is written when it returns false.
+func authorize(w http.ResponseWriter, r *http.Request, logger *slog.Logger, role string) (*claims.Claims, bool) {
+	claims, ok := r.Context().Value(auth.ClaimsContext).(*claims.Claims)
+	if !ok || claims == nil {
+		logger.Error("failed to read claims from context")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return nil, false
+	}
+
+	if !claims.HasRole(role) {
+		logger.Error("user doesn't have correct permissions", "role", role, "user_id", claims.Subject)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return nil, false
+	}
+
+	return claims, true
+}
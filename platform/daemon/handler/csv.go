@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/render"
+)
+
+const (
+	mediaTypeCSV       = "text/csv"
+	mediaTypeMultipart = "multipart/form-data"
+
+	csvMaxMemory = 32 << 20
+)
+
+// csvUploadError collects the errors of every CSV file of an upload.
+type csvUploadError struct {
+	files []*model.CSVError
+}
+
+func (e *csvUploadError) Error() string {
+	msgs := make([]string, 0, len(e.files))
+	for _, file := range e.files {
+		msgs = append(msgs, file.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+type csvCellError struct {
+	Kind   string `json:"kind"`
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+// isCSVUpload reports whether a request body holds CSV files, either a single
+// one or one per kind as a multipart form.
+func isCSVUpload(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == mediaTypeCSV || mediaType == mediaTypeMultipart
+}
+
+// decodeCSVBatch reads a batch from CSV. A plain CSV body holds the kind named
+// by the kind query parameter. Multipart forms hold a file per kind, named by
+// the form field. Version and force come from the query or the form.
+func decodeCSVBatch(r *http.Request) (*model.BlueprintBatchRequest, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, NewErrInvalidMediaType(r.Header.Get("Content-Type"))
+	}
+
+	batch := &model.BlueprintBatchRequest{
+		Blueprints: make(map[string][]registry.Request),
+	}
+
+	failed := &csvUploadError{}
+
+	read := func(name string, open func() (io.ReadCloser, error)) error {
+		kind, err := blueprint.Find(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		f, err := open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		defs, err := model.ReadCSV(kind, f)
+
+		var csvErr *model.CSVError
+		if errors.As(err, &csvErr) {
+			failed.files = append(failed.files, csvErr)
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", kind.Name, err)
+		}
+
+		batch.Blueprints[kind.Name] = append(batch.Blueprints[kind.Name], defs...)
+
+		return nil
+	}
+
+	switch mediaType {
+	case mediaTypeCSV:
+		open := func() (io.ReadCloser, error) { return r.Body, nil }
+
+		if err := read(r.URL.Query().Get("kind"), open); err != nil {
+			return nil, err
+		}
+	case mediaTypeMultipart:
+		if err := r.ParseMultipartForm(csvMaxMemory); err != nil {
+			return nil, errCSVForm{err}
+		}
+
+		names := make([]string, 0, len(r.MultipartForm.File))
+		for name := range r.MultipartForm.File {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			for _, header := range r.MultipartForm.File[name] {
+				open := func() (io.ReadCloser, error) { return header.Open() }
+
+				if err := read(name, open); err != nil {
+					return nil, err
+				}
+			}
+		}
+	default:
+		return nil, NewErrInvalidMediaType(mediaType)
+	}
+
+	if len(failed.files) > 0 {
+		return nil, failed
+	}
+
+	batch.Version = r.FormValue("version")
+
+	if raw := r.FormValue("force"); raw != "" {
+		if batch.Force, err = strconv.ParseBool(raw); err != nil {
+			return nil, errCSVForm{fmt.Errorf("invalid force: %s", raw)}
+		}
+	}
+
+	return batch, nil
+}
+
+// errCSVForm is a malformed form or query of a CSV upload.
+type errCSVForm struct {
+	err error
+}
+
+func (e errCSVForm) Error() string { return e.err.Error() }
+func (e errCSVForm) Unwrap() error { return e.err }
+
+// writeCSVError reports the errors of a CSV upload per row and column.
+func writeCSVError(w http.ResponseWriter, r *http.Request, err *csvUploadError) {
+	cells := make([]csvCellError, 0)
+
+	for _, file := range err.files {
+		for _, cell := range file.Cells {
+			cells = append(cells, csvCellError{
+				Kind:   file.Kind,
+				Row:    cell.Row,
+				Column: cell.Column,
+				Error:  cell.Err.Error(),
+			})
+		}
+	}
+
+	render.Status(r, http.StatusBadRequest)
+	render.JSON(w, r, map[string]any{
+		"errors": cells,
+	})
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const csvVersion = "13.0.0"
+
+func TestCSVBlueprints(t *testing.T) {
+	setupStores(t)
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Get("/registry/export/{version}", ExportBlueprints())
+
+	post := func(query, contentType string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/registry/blueprints"+query, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req = withClaims(req, "registry:write")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	form := &bytes.Buffer{}
+	mw := multipart.NewWriter(form)
+	require.NoError(t, mw.WriteField("version", csvVersion))
+
+	files := map[string]string{
+		"resources": "name,slug\nWood,wood\nStone,stone\n",
+		"building":  "name,slug,build_time,cost.wood\nHut,hut,5s,3\n",
+	}
+
+	for field, content := range files {
+		fw, err := mw.CreateFormFile(field, field+".csv")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, mw.Close())
+
+	rec := post("", mw.FormDataContentType(), form.Bytes())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	buildings := "name,slug,build_time,cost.stone,cost.wood\nHouse,house,10s,5,10\n"
+
+	rec = post("?kind=building&version="+csvVersion, "text/csv; charset=utf-8", []byte(buildings))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = post("?version="+csvVersion, "text/csv", []byte(buildings))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = post("?kind=building&version="+csvVersion, "text/csv", []byte("name,slug,build_time,cost.wood\nBarn,barn,soon,1\nShed,shed,1s,many\n"))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var failed struct {
+		Errors []csvCellError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &failed))
+	require.Len(t, failed.Errors, 2)
+	assert.Equal(t, csvCellError{Kind: "building", Row: 2, Column: "build_time", Error: failed.Errors[0].Error}, failed.Errors[0])
+	assert.Equal(t, csvCellError{Kind: "building", Row: 3, Column: "cost.wood", Error: failed.Errors[1].Error}, failed.Errors[1])
+
+	export := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/registry/export/"+csvVersion+query, nil)
+		req.Header.Set("Accept", "text/csv")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec = export("?kind=buildings")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	assert.Equal(t, []string{
+		"name,slug,build_time,cost.stone,cost.wood",
+		"House,house,10s,5,10",
+		"Hut,hut,5s,,3",
+	}, lines)
+
+	rec = export("?kind=resource")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "name,slug\nStone,stone\nWood,wood\n", rec.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, export("").Code)
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/registrar"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var errBlueprintDeprecated = errors.New("blueprint is deprecated")
+
+func DeleteBlueprint() http.HandlerFunc {
+	logger := slog.Default().With("context", "DeleteBlueprint")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authorize(w, r, logger, "dev.avalon.cool:registry:delete")
+		if !ok {
+			return
+		}
+
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+		slug := chi.URLParam(r, "slug")
+
+		kind, err := blueprint.Get(chi.URLParam(r, "kind"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		if kind.Delete == nil {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		exists, err := blueprintExists(r.Context(), version, kind.Name, slug)
+		if err != nil {
+			logger.Error("failed to look up blueprint", "error", err, "version", version, "kind", kind.Name, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if !exists {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		if err := registrar.CheckFrozen(r.Context(), version); err != nil {
+			logger.Debug("version is not writable", "error", err, "version", version)
+			writeVersionError(w, err)
+
+			return
+		}
+
+		store, err := metadata.Get()
+		if err != nil {
+			logger.Error("failed to get metadata store", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if err := kind.Delete(r.Context(), version, slug); err != nil {
+			switch {
+			case errors.Is(err, blueprint.ErrNotFound):
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			case errors.Is(err, blueprint.ErrUnsupported):
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			default:
+				logger.Error("failed to delete blueprint", "error", err, "version", version, "kind", kind.Name, "slug", slug)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err := store.DeleteBlueprint(r.Context(), version, kind.Name, slug); err != nil {
+			logger.Error("failed to delete blueprint metadata", "error", err, "version", version, "kind", kind.Name, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		logger.Info("deleted blueprint", "version", version, "kind", kind.Name, "slug", slug, "user_id", claims.Subject)
+
+		render.JSON(w, r, map[string]string{"status": "OK"})
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// DeprecateBlueprint sets or clears the deprecation flag on a blueprint in a
+// live version. Deprecated buildings can't be built any more, but they
+// still resolve for buildings that already exist.
+func DeprecateBlueprint(deprecated bool) http.HandlerFunc {
+	logger := slog.Default().With("context", "DeprecateBlueprint")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authorize(w, r, logger, "dev.avalon.cool:registry:deprecate")
+		if !ok {
+			return
+		}
+
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+		kind := chi.URLParam(r, "kind")
+		slug := chi.URLParam(r, "slug")
+
+		store, err := metadata.Get()
+		if err != nil {
+			logger.Error("failed to get metadata store", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		v, err := store.GetVersion(r.Context(), version)
+		if err != nil {
+			if errors.Is(err, metadata.ErrNotFound) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				logger.Error("failed to get version", "error", err, "version", version)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if v.State != metadata.StateLive {
+			http.Error(w, "only blueprints of live versions can be deprecated", http.StatusConflict)
+			return
+		}
+
+		exists, err := blueprintExists(r.Context(), version, kind, slug)
+		if err != nil {
+			logger.Error("failed to look up blueprint", "error", err, "version", version, "kind", kind, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if !exists {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		bp, err := metadata.GetOrNewBlueprint(r.Context(), store, version, kind, slug)
+		if err != nil {
+			logger.Error("failed to get blueprint metadata", "error", err, "version", version, "kind", kind, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if deprecated {
+			bp.Deprecate(claims.Subject)
+		} else {
+			bp.Undeprecate()
+		}
+
+		if err := store.SaveBlueprint(r.Context(), bp); err != nil {
+			logger.Error("failed to save blueprint metadata", "error", err, "version", version, "kind", kind, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		logger.Info("updated blueprint deprecation", "version", version, "kind", kind, "slug", slug, "deprecated", deprecated, "user_id", claims.Subject)
+
+		render.JSON(w, r, bp)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// checkDeprecated returns errBlueprintDeprecated if the blueprint has been
+// deprecated in the given version.
+func checkDeprecated(ctx context.Context, version, kind, slug string) error {
+	store, err := metadata.Get()
+	if err != nil {
+		return err
+	}
+
+	bp, err := store.GetBlueprint(ctx, version, kind, slug)
+	if err != nil {
+		if errors.Is(err, metadata.ErrNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if bp.Deprecated {
+		return errBlueprintDeprecated
+	}
+
+	return nil
+}
+
+func blueprintExists(ctx context.Context, version, kind, slug string) (bool, error) {
+	registered, err := blueprint.Get(kind)
+	if err != nil {
+		return false, nil
+	}
+
+	if _, err := registered.Lookup(ctx, version, slug); err != nil {
+		if errors.Is(err, blueprint.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
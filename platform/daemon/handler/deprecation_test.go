@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deprecationVersion = "4.0.0"
+
+func TestDeleteAndDeprecateBlueprint(t *testing.T) {
+	setupStores(t)
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Post("/registry/promote/{version}/{state}", PromoteVersion(nil))
+	router.Delete("/registry/blueprint/{version}/{kind}/{slug}", DeleteBlueprint())
+	router.Put("/registry/blueprint/{version}/{kind}/{slug}/deprecated", DeprecateBlueprint(true))
+	router.Delete("/registry/blueprint/{version}/{kind}/{slug}/deprecated", DeprecateBlueprint(false))
+	router.Post("/build", Build(nil))
+
+	batch, err := json.Marshal(model.BlueprintBatchRequest{
+		Version: deprecationVersion,
+		Blueprints: map[string][]registry.Request{
+			model.KindBuilding: {
+				registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"},
+				registry.BuildingBlueprintRequest{Name: "Hut", Slug: "hut", BuildTime: "5s"},
+				registry.BuildingBlueprintRequest{Name: "Mill", Slug: "mill", BuildTime: "20s"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	do := func(method, path string, body []byte, roles ...string) int {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = withClaims(req, roles...)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	base := "/registry/blueprint/" + deprecationVersion + "/building/"
+
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/registry/blueprints", batch, "registry:write"))
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodDelete, base+"hut", nil))
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, base+"hut", nil, "registry:delete"))
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, base+"hut", nil, "registry:delete"))
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodDelete, "/registry/blueprint/"+deprecationVersion+"/bogus/hut", nil, "registry:delete"))
+
+	assert.Equal(t, http.StatusConflict, do(http.MethodPut, base+"house/deprecated", nil, "registry:deprecate"))
+
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/registry/promote/"+deprecationVersion+"/staging", nil, "registry:promote-staging"))
+	assert.Equal(t, http.StatusConflict, do(http.MethodPut, base+"house/deprecated", nil, "registry:deprecate"))
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/registry/promote/"+deprecationVersion+"/live", nil, "registry:promote-live"))
+
+	assert.Equal(t, http.StatusConflict, do(http.MethodDelete, base+"house", nil, "registry:delete"))
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPut, base+"hut/deprecated", nil, "registry:deprecate"))
+
+	assert.NoError(t, checkDeprecated(context.Background(), deprecationVersion, model.KindBuilding, "house"))
+
+	assert.Equal(t, http.StatusOK, do(http.MethodPut, base+"house/deprecated", nil, "registry:deprecate"))
+	assert.ErrorIs(t, checkDeprecated(context.Background(), deprecationVersion, model.KindBuilding, "house"), errBlueprintDeprecated)
+
+	exists, err := blueprintExists(context.Background(), deprecationVersion, model.KindBuilding, "house")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, base+"house/deprecated", nil, "registry:deprecate"))
+	assert.NoError(t, checkDeprecated(context.Background(), deprecationVersion, model.KindBuilding, "house"))
+
+	// Builds are refused for the requested building only.
+	cluster.SetActive(deprecationVersion)
+	t.Cleanup(func() { cluster.SetActive("") })
+
+	assert.Equal(t, http.StatusOK, do(http.MethodPut, base+"mill/deprecated", nil, "registry:deprecate"))
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/build", []byte(`{"building": "mill"}`), "inventory:write"))
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/build", []byte(`{}`), "inventory:write"))
+}
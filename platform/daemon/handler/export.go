@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/go-chi/render"
+	"gopkg.in/yaml.v3"
+)
+
+func ExportBlueprints() http.HandlerFunc {
+	logger := slog.Default().With("context", "ExportBlueprints")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+
+		mediaType := negotiate(r.Header.Get("Accept"), mediaTypeJSON, mediaTypeYAML, mediaTypeTarGz, mediaTypeXTarGz, mediaTypeCSV)
+		if mediaType == "" {
+			http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+			return
+		}
+
+		// A CSV file holds a single kind.
+		var kind *blueprint.Kind
+
+		if mediaType == mediaTypeCSV {
+			var err error
+			if kind, err = blueprint.Find(r.URL.Query().Get("kind")); err != nil {
+				http.Error(w, fmt.Sprintf("CSV exports need a kind: %s", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		batch, err := exportBatch(r.Context(), version)
+		if err != nil {
+			logger.Error("failed to export blueprints", "error", err, "version", version)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if batch.Len() == 0 {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		// Display text and templates are kept by the gateway, CSV files only
+		// hold the resolved definitions.
+		if mediaType != mediaTypeCSV {
+			compact := false
+
+			if raw := r.URL.Query().Get("compact"); raw != "" {
+				if compact, err = strconv.ParseBool(raw); err != nil {
+					http.Error(w, fmt.Sprintf("invalid compact: %s", raw), http.StatusBadRequest)
+					return
+				}
+			}
+
+			if err := annotateBatch(r.Context(), batch, compact); err != nil {
+				logger.Error("failed to export blueprint annotations", "error", err, "version", version)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+				return
+			}
+		}
+
+		switch mediaType {
+		case mediaTypeYAML:
+			raw, err := yaml.Marshal(batch)
+			if err != nil {
+				logger.Error("failed to encode export", "error", err, "version", version)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+				return
+			}
+
+			w.Header().Set("Content-Type", mediaTypeYAML)
+			w.Write(raw) //nolint
+		case mediaTypeTarGz, mediaTypeXTarGz:
+			w.Header().Set("Content-Type", mediaType)
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("blueprints-%s.tar.gz", version)))
+
+			if err := model.WriteTarGz(w, batch); err != nil {
+				logger.Error("failed to write export bundle", "error", err, "version", version)
+			}
+		case mediaTypeCSV:
+			defs := batch.Blueprints[kind.Name]
+			if len(defs) == 0 {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+
+			w.Header().Set("Content-Type", mediaTypeCSV)
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("blueprints-%s-%s.csv", version, kind.Collection)))
+
+			if err := model.WriteCSV(kind, w, defs); err != nil {
+				logger.Error("failed to write CSV export", "error", err, "version", version, "kind", kind.Name)
+			}
+		default:
+			render.JSON(w, r, batch)
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// exportBatch reads a whole version in the shape accepted by
+// AddBlueprintBatch.
+func exportBatch(ctx context.Context, version string) (*model.BlueprintBatchRequest, error) {
+	set, err := loadVersion(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &model.BlueprintBatchRequest{
+		Version: version,
+	}
+
+	for _, kind := range blueprint.Held() {
+		for _, bp := range set.Set[kind.Name] {
+			def, err := kind.Request(bp)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", kind.Name, bp.GetSlug(), err)
+			}
+
+			if err := batch.Add(model.BlueprintRequest{Kind: kind.Name, Definition: def}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return batch, nil
+}
+
+// annotateBatch attaches the display text of the blueprints of the batch, and
+// with compact the templates they were resolved from. Annotations of
+// blueprints the batch doesn't hold are ignored.
+func annotateBatch(ctx context.Context, batch *model.BlueprintBatchRequest, compact bool) error {
+	store, err := metadata.Get()
+	if err != nil {
+		return err
+	}
+
+	annotations, err := store.ListBlueprints(ctx, batch.Version)
+	if err != nil {
+		return err
+	}
+
+	held := make(map[string]bool)
+	for name, defs := range batch.Blueprints {
+		for _, def := range defs {
+			held[name+"/"+def.GetSlug()] = true
+		}
+	}
+
+	for _, annotation := range annotations {
+		if !held[annotation.Kind+"/"+annotation.Slug] {
+			continue
+		}
+
+		batch.SetLocales(annotation.Kind, annotation.Slug, annotation.Locales)
+
+		if !compact || annotation.Extends == "" {
+			continue
+		}
+
+		template, err := model.NewTemplate(annotation.Extends, annotation.Overrides)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", annotation.Kind, annotation.Slug, err)
+		}
+
+		if batch.Templates == nil {
+			batch.Templates = make(map[string][]model.Template)
+		}
+
+		batch.Templates[annotation.Kind] = append(batch.Templates[annotation.Kind], template)
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/registrar"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+)
+
+const exportVersion = "2.0.0"
+
+func TestExportBlueprints(t *testing.T) {
+	setupStores(t)
+
+	expected := &model.BlueprintBatchRequest{
+		Version: exportVersion,
+		Blueprints: map[string][]registry.Request{
+			model.KindBuilding: {
+				registry.BuildingBlueprintRequest{
+					Name:       "House",
+					Slug:       "house",
+					BuildTime:  "10s",
+					Cost:       registry.ResourceList{{Resource: "wood", Amount: 5}},
+					Production: []registry.Production{},
+				},
+			},
+			model.KindResource: {
+				registry.ResourceBlueprintRequest{Name: "Wood", Slug: "wood"},
+			},
+		},
+	}
+
+	for _, req := range expected.Requests() {
+		require.NoError(t, registrar.SaveBlueprint(context.Background(), &req, registrar.NewTrail("")))
+	}
+
+	router := chi.NewRouter()
+	router.Get("/registry/export/{version}", ExportBlueprints())
+
+	tests := []struct {
+		label          string
+		accept         string
+		expectedStatus int
+		expectedType   string
+		decode         func([]byte) (*model.BlueprintBatchRequest, error)
+	}{
+		{
+			label:          "json",
+			accept:         "application/json",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/json",
+			decode: func(d []byte) (*model.BlueprintBatchRequest, error) {
+				var b model.BlueprintBatchRequest
+				return &b, json.Unmarshal(d, &b)
+			},
+		},
+		{
+			label:          "yaml",
+			accept:         "application/yaml",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/yaml",
+			decode: func(d []byte) (*model.BlueprintBatchRequest, error) {
+				var b model.BlueprintBatchRequest
+				return &b, yaml.Unmarshal(d, &b)
+			},
+		},
+		{
+			label:          "tar.gz",
+			accept:         "application/gzip",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/gzip",
+			decode: func(d []byte) (*model.BlueprintBatchRequest, error) {
+				return model.ReadTarGz(bytes.NewReader(d))
+			},
+		},
+		{
+			label:          "not-acceptable",
+			accept:         "text/html",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/registry/export/v"+exportVersion, nil)
+			req.Header.Set("Accept", tt.accept)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.decode == nil {
+				return
+			}
+
+			assert.Contains(t, rec.Header().Get("Content-Type"), tt.expectedType)
+
+			batch, err := tt.decode(rec.Body.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, expected, batch)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
+
+const templateVersion = "14.0.0"
+
+func TestBlueprintTemplates(t *testing.T) {
+	setupStores(t)
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprint", AddBlueprint())
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Get("/registry/export/{version}", ExportBlueprints())
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req = withClaims(req, "registry:write")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := post("/registry/blueprints", `{"version": "`+templateVersion+`",
+		"resources": [{"name": "Wood", "slug": "wood"}],
+		"buildings": [
+			{"name": "House", "slug": "house", "build_time": "10s", "cost": [{"resource": "wood", "amount": 10}]},
+			{"extends": "house", "slug": "big-house", "name": "Big House", "build_time": "20s"}
+		]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = post("/registry/blueprint", `{"kind": "building", "version": "`+templateVersion+`", "extends": "big-house",
+		"body": {"slug": "huge-house", "name": "Huge House"}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = post("/registry/blueprint", `{"kind": "building", "version": "`+templateVersion+`", "extends": "castle",
+		"body": {"slug": "tower", "name": "Tower"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = post("/registry/blueprints", `{"version": "`+templateVersion+`", "buildings": [
+		{"extends": "b", "slug": "a", "name": "A"},
+		{"extends": "a", "slug": "b", "name": "B"}
+	]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "a -> b -> a")
+
+	export := func(query string) map[string]map[string]any {
+		req := httptest.NewRequest(http.MethodGet, "/registry/export/"+templateVersion+query, nil)
+		req.Header.Set("Accept", "application/json")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var batch struct {
+			Buildings []map[string]any `json:"buildings"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &batch))
+
+		bySlug := make(map[string]map[string]any)
+		for _, building := range batch.Buildings {
+			bySlug[building["slug"].(string)] = building
+		}
+
+		return bySlug
+	}
+
+	resolved := export("")
+	require.Len(t, resolved, 3)
+	assert.Equal(t, "Huge House", resolved["huge-house"]["name"])
+	assert.Equal(t, "20s", resolved["huge-house"]["build_time"])
+	assert.NotContains(t, resolved["huge-house"], "extends")
+
+	compact := export("?compact=true")
+	require.Len(t, compact, 3)
+	assert.Equal(t, map[string]any{"extends": "house", "slug": "big-house", "name": "Big House", "build_time": "20s"}, compact["big-house"])
+	assert.Equal(t, map[string]any{"extends": "big-house", "slug": "huge-house", "name": "Huge House"}, compact["huge-house"])
+	assert.Equal(t, "10s", compact["house"]["build_time"])
+
+	// Uploading a plain definition drops the template.
+	rec = post("/registry/blueprint", `{"kind": "building", "version": "`+templateVersion+`",
+		"body": {"slug": "huge-house", "name": "Huge House", "build_time": "30s"}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	compact = export("?compact=true")
+	assert.Equal(t, "30s", compact["huge-house"]["build_time"])
+	assert.NotContains(t, compact["huge-house"], "extends")
+}
+
+type pennantRequest struct {
+	Name   string `json:"name"`
+	Slug   string `json:"slug"`
+	Colour string `json:"colour"`
+}
+
+func (p pennantRequest) GetName() string { return p.Name }
+func (p pennantRequest) GetSlug() string { return p.Slug }
+
+// pennant is a held blueprint of a kind registered besides the built-in ones.
+type pennant struct {
+	*structpb.Struct
+}
+
+func (p pennant) GetSlug() string { return p.GetFields()["slug"].GetStringValue() }
+
+func TestExportRegisteredKind(t *testing.T) {
+	setupStores(t)
+
+	const (
+		sourceVersion = "16.0.0"
+		targetVersion = "16.1.0"
+	)
+
+	saved := make(map[string][]pennantRequest)
+
+	blueprint.Register(&blueprint.Kind{
+		Name:     "pennant",
+		Decode:   blueprint.Decoder[pennantRequest](),
+		Model:    pennantRequest{},
+		Required: []string{"name", "slug"},
+		Save: blueprint.Saver(func(_ context.Context, version string, def pennantRequest, _ bool) error {
+			saved[version] = append(saved[version], def)
+			return nil
+		}),
+		Lookup: blueprint.Lookup(func(_ context.Context, version, slug string) (pennantRequest, error) {
+			for _, def := range saved[version] {
+				if def.Slug == slug {
+					return def, nil
+				}
+			}
+
+			return pennantRequest{}, blueprint.ErrNotFound
+		}),
+		Loaded: func(context.Context) []blueprint.Blueprint { return nil },
+		Read: func(_ context.Context, version string) ([]blueprint.Blueprint, error) {
+			bps := make([]blueprint.Blueprint, 0, len(saved[version]))
+
+			for _, def := range saved[version] {
+				fields, err := structpb.NewStruct(map[string]any{"name": def.Name, "slug": def.Slug, "colour": def.Colour})
+				if err != nil {
+					return nil, err
+				}
+
+				bps = append(bps, pennant{fields})
+			}
+
+			return bps, nil
+		},
+		New: func() blueprint.Blueprint { return pennant{&structpb.Struct{}} },
+		Request: blueprint.Requester(func(p pennant) pennantRequest {
+			fields := p.GetFields()
+
+			return pennantRequest{
+				Name:   fields["name"].GetStringValue(),
+				Slug:   fields["slug"].GetStringValue(),
+				Colour: fields["colour"].GetStringValue(),
+			}
+		}),
+	})
+
+	source := &model.BlueprintBatchRequest{
+		Version: sourceVersion,
+		Blueprints: map[string][]registry.Request{
+			"pennant": {
+				pennantRequest{Name: "Blue", Slug: "blue", Colour: "#0000ff"},
+				pennantRequest{Name: "Red", Slug: "red", Colour: "#ff0000"},
+			},
+			model.KindResource: {
+				registry.ResourceBlueprintRequest{Name: "Wood", Slug: "wood"},
+			},
+		},
+	}
+
+	for _, req := range source.Requests() {
+		require.NoError(t, registrar.SaveBlueprint(context.Background(), &req, registrar.NewTrail("")))
+	}
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Get("/registry/export/{version}", ExportBlueprints())
+
+	export := func(version string) *model.BlueprintBatchRequest {
+		req := httptest.NewRequest(http.MethodGet, "/registry/export/"+version, nil)
+		req.Header.Set("Accept", "application/json")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var batch model.BlueprintBatchRequest
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &batch))
+
+		return &batch
+	}
+
+	exported := export(sourceVersion)
+	assert.Equal(t, source, exported)
+
+	// The export is imported as another version as it is.
+	exported.Version = targetVersion
+
+	body, err := json.Marshal(exported)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/registry/blueprints", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withClaims(req, "registry:write")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	assert.Equal(t, exported, export(targetVersion))
+}
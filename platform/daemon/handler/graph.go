@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+)
+
+const (
+	mediaTypeDOT = "text/vnd.graphviz"
+
+	edgeCost   = "cost"
+	edgeInput  = "input"
+	edgeOutput = "output"
+)
+
+type graphNode struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name,omitempty"`
+	Missing     bool   `json:"missing,omitempty"`
+	Orphaned    bool   `json:"orphaned,omitempty"`
+	Unreachable bool   `json:"unreachable,omitempty"`
+}
+
+// graphEdge follows the flow of resources: costs and production inputs point
+// from the resource to the building, outputs from the building to the
+// resource.
+type graphEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Type   string `json:"type"`
+	Amount uint64 `json:"amount"`
+}
+
+type graphResponse struct {
+	Version     string      `json:"version"`
+	Nodes       []graphNode `json:"nodes"`
+	Edges       []graphEdge `json:"edges"`
+	Orphaned    []string    `json:"orphaned"`
+	Unreachable []string    `json:"unreachable"`
+	Missing     []string    `json:"missing"`
+}
+
+func nodeID(kind, slug string) string {
+	return kind + "/" + slug
+}
+
+// newGraph links the buildings of set to the resources they cost, consume and
+// produce.
+//
+// Resources no building references are orphaned. A building is unreachable if
+// its cost can't be paid: it needs a resource that only unreachable buildings
+// produce. Resources no building produces are gathered and available from the
+// start, unless start lists the starting resources explicitly.
+func newGraph(set *versionSet, start []string) *graphResponse {
+	graph := &graphResponse{
+		Version:     set.Version,
+		Nodes:       make([]graphNode, 0, set.Len()),
+		Edges:       make([]graphEdge, 0),
+		Orphaned:    make([]string, 0),
+		Unreachable: make([]string, 0),
+		Missing:     make([]string, 0),
+	}
+
+	var (
+		resources  = make(map[string]int)
+		referenced = make(map[string]bool)
+		produced   = make(map[string]bool)
+	)
+
+	for _, resource := range set.Resources() {
+		resources[resource.GetSlug()] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, graphNode{
+			ID:   nodeID(model.KindResource, resource.GetSlug()),
+			Kind: model.KindResource,
+			Slug: resource.GetSlug(),
+			Name: resource.GetName(),
+		})
+	}
+
+	link := func(building string, list *proto.ResourceList, edgeType string) {
+		for _, item := range list.GetResources() {
+			referenced[item.GetName()] = true
+
+			edge := graphEdge{
+				From:   nodeID(model.KindResource, item.GetName()),
+				To:     nodeID(model.KindBuilding, building),
+				Type:   edgeType,
+				Amount: item.GetAmount(),
+			}
+
+			if edgeType == edgeOutput {
+				edge.From, edge.To = edge.To, edge.From
+				produced[item.GetName()] = true
+			}
+
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	buildings := make(map[string]int)
+
+	for _, building := range set.Buildings() {
+		buildings[building.GetSlug()] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, graphNode{
+			ID:   nodeID(model.KindBuilding, building.GetSlug()),
+			Kind: model.KindBuilding,
+			Slug: building.GetSlug(),
+			Name: building.GetName(),
+		})
+
+		link(building.GetSlug(), building.GetCost(), edgeCost)
+
+		for _, production := range building.GetProduction() {
+			link(building.GetSlug(), production.GetCost(), edgeInput)
+			link(building.GetSlug(), production.GetOutput(), edgeOutput)
+		}
+	}
+
+	// Referenced resources without a blueprint are added as missing nodes,
+	// so every edge has both ends.
+	missing := make([]string, 0)
+	for slug := range referenced {
+		if _, ok := resources[slug]; !ok {
+			missing = append(missing, slug)
+		}
+	}
+
+	sort.Strings(missing)
+
+	for _, slug := range missing {
+		id := nodeID(model.KindResource, slug)
+
+		graph.Nodes = append(graph.Nodes, graphNode{ID: id, Kind: model.KindResource, Slug: slug, Missing: true})
+		graph.Missing = append(graph.Missing, id)
+	}
+
+	for _, resource := range set.Resources() {
+		if !referenced[resource.GetSlug()] {
+			i := resources[resource.GetSlug()]
+			graph.Nodes[i].Orphaned = true
+			graph.Orphaned = append(graph.Orphaned, graph.Nodes[i].ID)
+		}
+	}
+
+	available := make(map[string]bool)
+
+	if len(start) > 0 {
+		for _, slug := range start {
+			available[slug] = true
+		}
+	} else {
+		for slug := range referenced {
+			if !produced[slug] {
+				available[slug] = true
+			}
+		}
+	}
+
+	affordable := func(list *proto.ResourceList) bool {
+		for _, item := range list.GetResources() {
+			if !available[item.GetName()] {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// Reachable buildings make their outputs available, which may make more
+	// buildings reachable, until nothing changes.
+	reachable := make(map[string]bool)
+	candidates := set.Buildings()
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, building := range candidates {
+			if !reachable[building.GetSlug()] && affordable(building.GetCost()) {
+				reachable[building.GetSlug()] = true
+				changed = true
+			}
+
+			if !reachable[building.GetSlug()] {
+				continue
+			}
+
+			for _, production := range building.GetProduction() {
+				if !affordable(production.GetCost()) {
+					continue
+				}
+
+				for _, item := range production.GetOutput().GetResources() {
+					if !available[item.GetName()] {
+						available[item.GetName()] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, building := range candidates {
+		if !reachable[building.GetSlug()] {
+			i := buildings[building.GetSlug()]
+			graph.Nodes[i].Unreachable = true
+			graph.Unreachable = append(graph.Unreachable, graph.Nodes[i].ID)
+		}
+	}
+
+	return graph
+}
+
+// DOT renders the graph for Graphviz. Flagged nodes are drawn in red, missing
+// resources dotted.
+func (g *graphResponse) DOT() []byte {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, "digraph %s {\n", strconv.Quote("blueprints "+g.Version))
+	buf.WriteString("\trankdir=LR;\n")
+
+	for _, node := range g.Nodes {
+		label := node.Name
+		if label == "" {
+			label = node.Slug
+		}
+
+		attrs := []string{"label=" + strconv.Quote(label)}
+
+		if node.Kind == model.KindBuilding {
+			attrs = append(attrs, "shape=box")
+		} else {
+			attrs = append(attrs, "shape=ellipse")
+		}
+
+		switch {
+		case node.Missing:
+			attrs = append(attrs, "style=dotted")
+		case node.Orphaned, node.Unreachable:
+			attrs = append(attrs, "color=red", "fontcolor=red")
+		}
+
+		fmt.Fprintf(buf, "\t%s [%s];\n", strconv.Quote(node.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, edge := range g.Edges {
+		label := fmt.Sprintf("%s %d", edge.Type, edge.Amount)
+		fmt.Fprintf(buf, "\t%s -> %s [label=%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strconv.Quote(label))
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+// GetBlueprintGraph returns the graph of buildings and the resources they
+// cost and produce, as JSON, YAML or Graphviz DOT.
+func GetBlueprintGraph() http.HandlerFunc {
+	logger := slog.Default().With("context", "GetBlueprintGraph")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+
+		mediaType := negotiate(r.Header.Get("Accept"), mediaTypeJSON, mediaTypeYAML, mediaTypeDOT)
+		if mediaType == "" {
+			http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+			return
+		}
+
+		var start []string
+		if raw := r.URL.Query().Get("start"); raw != "" {
+			for _, slug := range strings.Split(raw, ",") {
+				if slug = strings.TrimSpace(slug); slug != "" {
+					start = append(start, slug)
+				}
+			}
+		}
+
+		set, err := loadVersion(r.Context(), version)
+		if err != nil {
+			logger.Error("failed to load version", "error", err, "version", version)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if set.Empty() {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		graph := newGraph(set, start)
+
+		if mediaType == mediaTypeDOT {
+			w.Header().Set("Content-Type", mediaTypeDOT)
+			w.Write(graph.DOT()) //nolint
+
+			return
+		}
+
+		respond(w, r, graph)
+	}
+
+	return http.HandlerFunc(fn)
+}
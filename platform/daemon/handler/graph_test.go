@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const graphVersion = "15.0.0"
+
+func TestGetBlueprintGraph(t *testing.T) {
+	setupStores(t)
+
+	buildings := []registry.BuildingBlueprintRequest{
+		{Name: "Hut", Slug: "hut", BuildTime: "5s", Cost: registry.ResourceList{{Resource: "wood", Amount: 5}}},
+		{
+			Name: "Sawmill", Slug: "sawmill", BuildTime: "10s",
+			Cost: registry.ResourceList{{Resource: "wood", Amount: 10}},
+			Production: []registry.Production{{
+				Cost:           registry.ResourceList{{Resource: "wood", Amount: 2}},
+				Product:        registry.ResourceList{{Resource: "plank", Amount: 1}},
+				ProductionTime: "5s",
+			}},
+		},
+		{Name: "House", Slug: "house", BuildTime: "10s", Cost: registry.ResourceList{{Resource: "plank", Amount: 4}}},
+		{
+			Name: "Mine", Slug: "mine", BuildTime: "10s",
+			Cost: registry.ResourceList{{Resource: "iron", Amount: 1}},
+			Production: []registry.Production{{
+				Product:        registry.ResourceList{{Resource: "iron", Amount: 1}},
+				ProductionTime: "5s",
+			}},
+		},
+		{Name: "Forge", Slug: "forge", BuildTime: "10s", Cost: registry.ResourceList{{Resource: "iron", Amount: 3}}},
+		{Name: "Shrine", Slug: "shrine", BuildTime: "10s", Cost: registry.ResourceList{{Resource: "crystal", Amount: 1}}},
+	}
+
+	resources := []registry.ResourceBlueprintRequest{
+		{Name: "Wood", Slug: "wood"},
+		{Name: "Plank", Slug: "plank"},
+		{Name: "Iron", Slug: "iron"},
+		{Name: "Gold", Slug: "gold"},
+	}
+
+	for _, building := range buildings {
+		require.NoError(t, registry.SaveBuildingBlueprint(context.Background(), graphVersion, building, false))
+	}
+
+	for _, resource := range resources {
+		require.NoError(t, registry.SaveResourceBlueprint(context.Background(), graphVersion, resource, false))
+	}
+
+	router := chi.NewRouter()
+	router.Get("/registry/blueprint/{version}/graph", GetBlueprintGraph())
+
+	get := func(version, query, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/registry/blueprint/"+version+"/graph"+query, nil)
+		req.Header.Set("Accept", accept)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	tests := []struct {
+		label               string
+		query               string
+		expectedUnreachable []string
+	}{
+		{label: "gathered", query: "", expectedUnreachable: []string{"building/forge", "building/mine"}},
+		{label: "start", query: "?start=plank,crystal", expectedUnreachable: []string{"building/forge", "building/hut", "building/mine", "building/sawmill"}},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			rec := get(graphVersion, tt.query, "application/json")
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var graph graphResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &graph))
+
+			assert.Equal(t, []string{"resource/gold"}, graph.Orphaned)
+			assert.Equal(t, []string{"resource/crystal"}, graph.Missing)
+			assert.Equal(t, tt.expectedUnreachable, graph.Unreachable)
+			assert.Len(t, graph.Nodes, 11)
+			assert.Contains(t, graph.Edges, graphEdge{From: "building/sawmill", To: "resource/plank", Type: edgeOutput, Amount: 1})
+			assert.Contains(t, graph.Edges, graphEdge{From: "resource/wood", To: "building/sawmill", Type: edgeInput, Amount: 2})
+			assert.Contains(t, graph.Edges, graphEdge{From: "resource/plank", To: "building/house", Type: edgeCost, Amount: 4})
+		}
+
+		t.Run(tt.label, tf)
+	}
+
+	rec := get(graphVersion, "", mediaTypeDOT)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, mediaTypeDOT, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `digraph "blueprints 15.0.0" {`)
+	assert.Contains(t, rec.Body.String(), `"resource/gold" [label="Gold", shape=ellipse, color=red, fontcolor=red];`)
+	assert.Contains(t, rec.Body.String(), `"building/sawmill" -> "resource/plank" [label="output 1"];`)
+
+	assert.Equal(t, http.StatusNotFound, get("15.0.1", "", "application/json").Code)
+	assert.Equal(t, http.StatusNotAcceptable, get(graphVersion, "", "image/png").Code)
+}
@@ -1,15 +1,19 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth"
 	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth/claims"
 	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth/provider"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
 	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/handler/middleware"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
 	"github.com/GnarloqGames/genesis-avalon-kit/database"
 	"github.com/GnarloqGames/genesis-avalon-kit/proto"
 	"github.com/GnarloqGames/genesis-avalon-kit/transport"
@@ -25,7 +29,13 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+var payloadHookOnce = &sync.Once{}
+
 func Handler(bus *transport.Connection, verifier provider.TokenVerifier) http.Handler {
+	payloadHookOnce.Do(func() {
+		cluster.OnLoad(preparePayload)
+	})
+
 	meters, err := newMeters()
 	if err != nil {
 		slog.Error("failed to create meters", "error", err)
@@ -38,19 +48,22 @@ func Handler(bus *transport.Connection, verifier provider.TokenVerifier) http.Ha
 		AllowedOrigins: []string{"https://*", "http://*"},
 		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Accept-Language", "Authorization", "Content-Type", "X-CSRF-Token", "If-None-Match", middleware.HeaderExpectedBlueprintVersion},
+		ExposedHeaders:   []string{"Link", "ETag", "Content-Language", middleware.HeaderBlueprintVersion, middleware.HeaderBlueprintDigest},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
-	r.Use(middleware.Metrics(meters, []string{"/favicon.ico", "/metrics"}))
-	r.Use(middleware.Tracing([]string{"/favicon.ico", "/metrics"}))
-	r.Use(middleware.Logging([]string{"/favicon.ico", "/metrics"}))
+	r.Use(middleware.Metrics(meters, []string{"/favicon.ico", "/metrics", "/health"}))
+	r.Use(middleware.Tracing([]string{"/favicon.ico", "/metrics", "/health"}))
+	r.Use(middleware.Logging([]string{"/favicon.ico", "/metrics", "/health"}))
+	r.Use(middleware.BlueprintVersion(activeBlueprints))
 
 	r.Handle("/metrics", promhttp.Handler())
+	r.Get("/health", Health())
 
 	r.Group(func(rr chi.Router) {
 		rr.Use(auth.Middleware(verifier))
+		rr.Use(middleware.ExpectBlueprintVersion(activeBlueprints, expectedVersion))
 		rr.Post("/build", Build(bus))
 		rr.Get("/buildings", ListBuildings())
 	})
@@ -58,13 +71,30 @@ func Handler(bus *transport.Connection, verifier provider.TokenVerifier) http.Ha
 	r.Group(func(rr chi.Router) {
 		rr.Use(auth.Middleware(verifier))
 
-		rr.Post("/registry/reload/{version}", ReloadBlueprints())
+		rr.Post("/registry/blueprint", AddBlueprint())
+		rr.Post("/registry/blueprints", AddBlueprintBatch())
+		rr.Post("/registry/import", ImportBlueprints())
+		rr.Post("/registry/reload/{version}", ReloadBlueprints(bus))
+		rr.Get("/registry/webhooks/deliveries", ListWebhookDeliveries())
+		rr.Get("/registry/audit", ListAudit())
+		rr.Post("/registry/promote/{version}/{state}", PromoteVersion(bus))
+		rr.Delete("/registry/blueprint/{version}/{kind}/{slug}", DeleteBlueprint())
+		rr.Put("/registry/blueprint/{version}/{kind}/{slug}/deprecated", DeprecateBlueprint(true))
+		rr.Delete("/registry/blueprint/{version}/{kind}/{slug}/deprecated", DeprecateBlueprint(false))
+		rr.Put("/registry/blueprint/{version}/{kind}/{slug}/assets/{name}", UploadAsset())
+		rr.Delete("/registry/blueprint/{version}/{kind}/{slug}/assets/{name}", DeleteAsset())
 	})
 
-	r.Post("/registry/blueprint", AddBlueprint())
-	r.Post("/registry/blueprints", AddBlueprintBatch())
+	r.Get("/registry/blueprint/{version}/search", SearchBlueprints())
+	r.Get("/registry/blueprint/{version}/graph", GetBlueprintGraph())
+	r.Get("/registry/schema/{kind}", GetSchema())
 	r.Get("/registry/blueprint/{version}/{kind}/{slug}", GetBlueprint())
+	r.Get("/registry/blueprint/{version}/{kind}/{slug}/assets", ListAssets())
+	r.Get("/registry/blueprint/{version}/{kind}/{slug}/assets/{name}", GetAsset())
 	r.Get("/registry/blueprint/{version}", GetBlueprints())
+	r.Get("/registry/export/{version}", ExportBlueprints())
+	r.Get("/registry/versions", ListVersions())
+	r.Post("/registry/simulate", SimulateEconomy())
 
 	return r
 }
@@ -87,6 +117,34 @@ func Build(bus *transport.Connection) http.HandlerFunc {
 			return
 		}
 
+		build, err := decodeRequest[*model.BuildRequest](r)
+		if err != nil {
+			logger.Debug("failed to decode build request", "error", err)
+			writeDecodeError(w, err)
+
+			return
+		}
+
+		if err := build.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		name := build.Building
+		version := cluster.Active()
+
+		if err := checkDeprecated(r.Context(), version, model.KindBuilding, name); err != nil {
+			if errors.Is(err, errBlueprintDeprecated) {
+				logger.Debug("refusing to build deprecated building", "name", name, "version", version)
+				http.Error(w, err.Error(), http.StatusConflict)
+			} else {
+				logger.Error("failed to check blueprint deprecation", "error", err, "name", name, "version", version)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
 		src := map[string]any{"owner": claims.Subject}
 		context, err := structpb.NewStruct(src)
 
@@ -102,7 +160,7 @@ func Build(bus *transport.Connection) http.HandlerFunc {
 				TraceID:   "",
 				Timestamp: timestamppb.Now(),
 			},
-			Name:     "house",
+			Name:     name,
 			Duration: "10s",
 			Context:  context,
 		}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/snapshot"
+	"github.com/go-chi/render"
+)
+
+const (
+	healthOK       = "OK"
+	healthDegraded = "DEGRADED"
+)
+
+type healthResponse struct {
+	Status     string          `json:"status"`
+	Version    string          `json:"version"`
+	Blueprints snapshot.Status `json:"blueprints"`
+}
+
+// Health reports whether the gateway serves blueprints from the registry or
+// from a stale snapshot. Stale gateways still serve requests, so both are 200.
+func Health() http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		response := healthResponse{
+			Status:     healthOK,
+			Version:    cluster.Active(),
+			Blueprints: snapshot.Current(),
+		}
+
+		if response.Blueprints.Stale {
+			response.Status = healthDegraded
+		}
+
+		render.JSON(w, r, response)
+	}
+
+	return http.HandlerFunc(fn)
+}
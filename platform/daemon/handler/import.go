@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/registrar"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	mediaTypeNDJSON = "application/x-ndjson"
+
+	importStatusOK    = "OK"
+	importStatusError = "ERROR"
+	importStatusDone  = "DONE"
+
+	// importMaxLine caps a single NDJSON line, which bounds the memory used
+	// by an import no matter how large the upload is.
+	importMaxLine      = 4 << 20
+	importProgressStep = 100
+	importIdleTimeout  = 30 * time.Second
+)
+
+// errSkipItem marks an item that couldn't be decoded but doesn't stop the
+// rest of the stream from being read.
+type errSkipItem struct {
+	err error
+}
+
+func (e errSkipItem) Error() string { return e.err.Error() }
+func (e errSkipItem) Unwrap() error { return e.err }
+
+type blueprintReader interface {
+	// Next decodes the next blueprint into req. It returns io.EOF once the
+	// stream is exhausted.
+	Next(req *model.BlueprintRequest) error
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), importMaxLine)
+
+	return &ndjsonReader{scanner: scanner}
+}
+
+func (n *ndjsonReader) Next(req *model.BlueprintRequest) error {
+	for n.scanner.Scan() {
+		line := bytes.TrimSpace(n.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if err := json.Unmarshal(line, req); err != nil {
+			return errSkipItem{err: err}
+		}
+
+		return nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
+
+type yamlStreamReader struct {
+	decoder *yaml.Decoder
+}
+
+func (y *yamlStreamReader) Next(req *model.BlueprintRequest) error {
+	err := y.decoder.Decode(req)
+
+	// The document has been read in full, so the stream can go on after a
+	// body that doesn't match its schema.
+	var schemaErr blueprint.SchemaError
+	if errors.As(err, &schemaErr) {
+		return errSkipItem{err: err}
+	}
+
+	return err
+}
+
+type importEvent struct {
+	Status  string `json:"status"`
+	Item    int    `json:"item,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+	Slug    string `json:"slug,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Saved   int    `json:"saved"`
+	Failed  int    `json:"failed"`
+}
+
+// ImportBlueprints saves a stream of blueprint requests, one per NDJSON line
+// or YAML document, as they are read. Progress is streamed back as NDJSON.
+func ImportBlueprints() http.HandlerFunc {
+	logger := slog.Default().With("context", "ImportBlueprints")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		claims, ok := authorize(w, r, logger, "dev.avalon.cool:registry:write")
+		if !ok {
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var reader blueprintReader
+
+		switch {
+		case mediaType == mediaTypeNDJSON:
+			reader = newNDJSONReader(r.Body)
+		case mediaFormat(mediaType) == formatYAML:
+			reader = &yamlStreamReader{decoder: yaml.NewDecoder(r.Body)}
+		default:
+			http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		encoder := json.NewEncoder(w)
+
+		emit := func(event importEvent) {
+			rc.SetWriteDeadline(time.Now().Add(importIdleTimeout)) //nolint
+
+			if err := encoder.Encode(event); err != nil {
+				logger.Debug("failed to write import progress", "error", err)
+				return
+			}
+
+			rc.Flush() //nolint
+		}
+
+		// HTTP/1.1 connections stop reading the upload once the response has
+		// been written to, progress is streamed while the body is still being
+		// read. HTTP/2 streams are always full duplex and report an error here.
+		rc.EnableFullDuplex() //nolint
+
+		w.Header().Set("Content-Type", mediaTypeNDJSON)
+		w.WriteHeader(http.StatusOK)
+
+		var (
+			item     = 0
+			saved    = 0
+			failed   = 0
+			writable = make(map[string]error)
+			trail    = registrar.NewTrail(claims.Subject)
+		)
+
+		for {
+			rc.SetReadDeadline(time.Now().Add(importIdleTimeout)) //nolint
+
+			var req model.BlueprintRequest
+
+			err := reader.Next(&req)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			item++
+
+			if err != nil {
+				failed++
+
+				emit(importEvent{Status: importStatusError, Item: item, Error: err.Error(), Saved: saved, Failed: failed})
+
+				var skip errSkipItem
+				if errors.As(err, &skip) {
+					continue
+				}
+
+				logger.Error("failed to read import stream", "error", err, "item", item)
+
+				break
+			}
+
+			if err := importBlueprint(r, &req, writable, trail); err != nil {
+				failed++
+
+				emit(importEvent{
+					Status:  importStatusError,
+					Item:    item,
+					Kind:    req.Kind,
+					Slug:    req.Slug(),
+					Version: req.Version,
+					Error:   err.Error(),
+					Saved:   saved,
+					Failed:  failed,
+				})
+
+				continue
+			}
+
+			saved++
+
+			if saved%importProgressStep == 0 {
+				emit(importEvent{Status: importStatusOK, Item: item, Saved: saved, Failed: failed})
+			}
+		}
+
+		logger.Info("imported blueprints", "saved", saved, "failed", failed)
+
+		emit(importEvent{Status: importStatusDone, Item: item, Saved: saved, Failed: failed})
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// importBlueprint saves a single streamed blueprint. The writability of each
+// version is only looked up once per import.
+func importBlueprint(r *http.Request, req *model.BlueprintRequest, writable map[string]error, trail *registrar.Trail) error {
+	if err := registrar.Resolve(r.Context(), req); err != nil {
+		return err
+	}
+
+	if err := registrar.Validate(req); err != nil {
+		return err
+	}
+
+	checked, ok := writable[req.Version]
+	if !ok {
+		checked = registrar.CheckWritable(r.Context(), req.Version)
+		writable[req.Version] = checked
+	}
+
+	if checked != nil {
+		return fmt.Errorf("version %s: %w", req.Version, checked)
+	}
+
+	return registrar.SaveBlueprint(r.Context(), req, trail)
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ndjsonBody = `{"kind":"building","version":"6.0.0","body":{"name":"House","slug":"house","build_time":"10s"}}
+{"kind":"bogus","version":"6.0.0","body":{"name":"Nothing"}}
+{not json}
+
+{"kind":"resource","version":"6.0.0","body":{"name":"Wood","slug":"wood"}}
+`
+
+	yamlStreamBody = `kind: building
+version: 6.1.0
+body:
+  name: Hut
+  slug: hut
+  build_time: 5s
+---
+kind: resource
+version: 6.1.0
+body:
+  name: Stone
+  slug: stone
+`
+)
+
+func readEvents(t *testing.T, body io.Reader) []importEvent {
+	t.Helper()
+
+	events := make([]importEvent, 0)
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var event importEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// largeImportBody returns an NDJSON upload of count resources, long enough for
+// progress to be written back while it's still being read.
+func largeImportBody(version string, count int) string {
+	var body strings.Builder
+
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&body, `{"kind":"resource","version":%q,"body":{"name":"Ore %d","slug":"ore-%d"}}`+"\n", version, i, i)
+	}
+
+	return body.String()
+}
+
+func TestImportBlueprints(t *testing.T) {
+	setupStores(t)
+
+	// Imports are sent to a server rather than a recorder, uploads are read
+	// while progress is written back on the same connection.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ImportBlueprints().ServeHTTP(w, withClaims(r, "registry:write"))
+	}))
+	defer server.Close()
+
+	// Larger than what the reader buffers on its first read, so the upload
+	// is still being read when progress is first written.
+	largeCount := 20*importProgressStep + 1
+
+	tests := []struct {
+		label          string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedErrors []int
+		expectedSaved  int
+	}{
+		{
+			label:          "ndjson",
+			contentType:    "application/x-ndjson",
+			body:           ndjsonBody,
+			expectedStatus: http.StatusOK,
+			expectedErrors: []int{2, 3},
+			expectedSaved:  2,
+		},
+		{
+			label:          "yaml",
+			contentType:    "application/yaml; charset=utf-8",
+			body:           yamlStreamBody,
+			expectedStatus: http.StatusOK,
+			expectedErrors: []int{},
+			expectedSaved:  2,
+		},
+		{
+			label:          "large",
+			contentType:    "application/x-ndjson",
+			body:           largeImportBody("6.2.0", largeCount),
+			expectedStatus: http.StatusOK,
+			expectedErrors: []int{},
+			expectedSaved:  largeCount,
+		},
+		{
+			label:          "unsupported",
+			contentType:    "application/json",
+			body:           "{}",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			res, err := http.Post(server.URL+"/registry/import", tt.contentType, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			defer res.Body.Close()
+
+			require.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			events := readEvents(t, res.Body)
+			require.NotEmpty(t, events)
+
+			failed := make([]int, 0)
+
+			for _, event := range events {
+				if event.Status == importStatusError {
+					failed = append(failed, event.Item)
+				}
+			}
+
+			assert.Equal(t, tt.expectedErrors, failed)
+
+			summary := events[len(events)-1]
+			assert.Equal(t, importStatusDone, summary.Status)
+			assert.Equal(t, tt.expectedSaved, summary.Saved)
+			assert.Equal(t, len(tt.expectedErrors), summary.Failed)
+		}
+
+		t.Run(tt.label, tf)
+	}
+
+	bp, err := registry.GetResourceBlueprint(context.Background(), "6.0.0", "wood")
+	require.NoError(t, err)
+	assert.Equal(t, "Wood", bp.Name)
+
+	last := fmt.Sprintf("ore-%d", largeCount-1)
+	bp, err = registry.GetResourceBlueprint(context.Background(), "6.2.0", last)
+	require.NoError(t, err)
+	assert.Equal(t, last, bp.Slug)
+}
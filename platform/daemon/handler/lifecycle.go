@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-kit/transport"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var promotionRoles = map[metadata.State]string{
+	metadata.StateStaging: "dev.avalon.cool:registry:promote-staging",
+	metadata.StateLive:    "dev.avalon.cool:registry:promote-live",
+}
+
+func PromoteVersion(bus *transport.Connection) http.HandlerFunc {
+	logger := slog.Default().With("context", "PromoteVersion")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		target, err := metadata.ParseState(chi.URLParam(r, "state"))
+		if err != nil || target == metadata.StateDraft {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		claims, ok := authorize(w, r, logger, promotionRoles[target])
+		if !ok {
+			return
+		}
+
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+
+		store, err := metadata.Get()
+		if err != nil {
+			logger.Error("failed to get metadata store", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		v, err := store.GetVersion(r.Context(), version)
+		if err != nil {
+			if errors.Is(err, metadata.ErrNotFound) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				logger.Error("failed to get version", "error", err, "version", version)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err := v.Promote(target, claims.Subject); err != nil {
+			logger.Debug("invalid promotion", "error", err, "version", version)
+			http.Error(w, err.Error(), http.StatusConflict)
+
+			return
+		}
+
+		if err := store.SaveVersion(r.Context(), v); err != nil {
+			logger.Error("failed to save version", "error", err, "version", version)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if err := store.SetChannel(r.Context(), target, version); err != nil {
+			logger.Error("failed to update channel", "error", err, "version", version, "channel", target)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		logger.Info("promoted blueprint version", "version", version, "state", target, "user_id", claims.Subject)
+
+		payloads.promoted(version)
+
+		response := map[string]any{
+			"status":  "OK",
+			"version": v,
+		}
+
+		if bus != nil {
+			response["instances"] = followChannel(r.Context(), bus, target, version, claims.Subject)
+		}
+
+		render.JSON(w, r, response)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// followChannel tells the gateways following the channel to reload, and
+// reports the reload like ReloadBlueprints does.
+func followChannel(ctx context.Context, bus *transport.Connection, channel metadata.State, version, subject string) []cluster.ReloadResult {
+	req := cluster.ReloadRequest{
+		Version:     version,
+		Channel:     string(channel),
+		RequestedBy: subject,
+	}
+
+	results, err := cluster.Reload(ctx, bus, req, cluster.DefaultTimeout)
+	if err != nil {
+		slog.Error("failed to broadcast reload request", "error", err, "version", version, "channel", channel)
+	}
+
+	reportReload(ctx, subject, version, results)
+
+	return results
+}
+
+func ListVersions() http.HandlerFunc {
+	logger := slog.Default().With("context", "ListVersions")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		store, err := metadata.Get()
+		if err != nil {
+			logger.Error("failed to get metadata store", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		versions, err := store.ListVersions(r.Context())
+		if err != nil {
+			logger.Error("failed to list versions", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if raw := r.URL.Query().Get("range"); raw != "" {
+			constraint, err := semver.NewConstraint(raw)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid range %q: %s", raw, err), http.StatusBadRequest)
+				return
+			}
+
+			versions = metadata.FilterVersions(versions, constraint)
+		}
+
+		metadata.SortVersions(versions)
+
+		channels := make(map[metadata.State]string)
+
+		for _, channel := range []metadata.State{metadata.StateStaging, metadata.StateLive} {
+			version, err := store.GetChannel(r.Context(), channel)
+			if err != nil && !errors.Is(err, metadata.ErrNotFound) {
+				logger.Error("failed to get channel", "error", err, "channel", channel)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+				return
+			}
+
+			if version != "" {
+				channels[channel] = version
+			}
+		}
+
+		render.JSON(w, r, map[string]any{
+			"versions": versions,
+			"channels": channels,
+		})
+	}
+
+	return http.HandlerFunc(fn)
+}
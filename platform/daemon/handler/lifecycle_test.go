@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/auth/claims"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint/mockwriter"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/registrar"
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	"github.com/GnarloqGames/genesis-avalon-kit/database/mock"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lifecycleVersion = "3.0.0"
+
+func withClaims(r *http.Request, roles ...string) *http.Request {
+	c := &claims.Claims{
+		Subject: "196176fd-6e54-49c2-9e49-eb81406c68d5",
+		Access: map[string]claims.Access{
+			"dev.avalon.cool": {
+				Resource: "dev.avalon.cool",
+				Roles:    roles,
+			},
+		},
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), auth.ClaimsContext, c))
+}
+
+// setupStores selects the mock database, with a writer to delete and replace
+// its blueprints, and fresh memory stores for the metadata and the audit
+// trail, so nothing written by earlier tests is left.
+func setupStores(t *testing.T) {
+	t.Helper()
+
+	database.SetKind(database.DriverMock)
+	metadata.SetKind(database.DriverMock)
+	audit.SetKind(database.DriverMock)
+	blueprint.SetWriter(mockwriter.New())
+
+	db, err := mock.Get()
+	require.NoError(t, err)
+
+	db.Buildings = make([]*proto.Building, 0)
+	db.BuildingBlueprints = make([]*proto.BuildingBlueprint, 0)
+	db.ResourceBlueprints = make([]*proto.ResourceBlueprint, 0)
+}
+
+func TestPromoteVersion(t *testing.T) {
+	setupStores(t)
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Post("/registry/promote/{version}/{state}", PromoteVersion(nil))
+
+	batch, err := json.Marshal(model.BlueprintBatchRequest{
+		Version: lifecycleVersion,
+		Blueprints: map[string][]registry.Request{
+			model.KindBuilding: {
+				registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	upload := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/registry/blueprints", bytes.NewReader(batch))
+		req.Header.Set("Content-Type", "application/json")
+		req = withClaims(req, "registry:write")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	promote := func(state string, roles ...string) int {
+		req := httptest.NewRequest(http.MethodPost, "/registry/promote/"+lifecycleVersion+"/"+state, nil)
+		req = withClaims(req, roles...)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, upload())
+
+	assert.Equal(t, http.StatusConflict, promote("live", "registry:promote-live"))
+	assert.Equal(t, http.StatusUnauthorized, promote("staging", "registry:promote-live"))
+	assert.Equal(t, http.StatusBadRequest, promote("bogus", "registry:promote-staging"))
+	assert.Equal(t, http.StatusOK, promote("staging", "registry:promote-staging"))
+
+	assert.Equal(t, http.StatusConflict, upload())
+
+	assert.Equal(t, http.StatusOK, promote("live", "registry:promote-live"))
+
+	store, err := metadata.Get()
+	require.NoError(t, err)
+
+	v, err := store.GetVersion(context.Background(), lifecycleVersion)
+	require.NoError(t, err)
+	assert.Equal(t, metadata.StateLive, v.State)
+
+	for _, channel := range []metadata.State{metadata.StateStaging, metadata.StateLive} {
+		version, err := store.GetChannel(context.Background(), channel)
+		require.NoError(t, err)
+		assert.Equal(t, lifecycleVersion, version)
+	}
+}
+
+func TestLegacyVersion(t *testing.T) {
+	setupStores(t)
+
+	ctx := context.Background()
+
+	// Versions written before lifecycle records were kept have blueprints
+	// but no record.
+	for _, version := range []string{"24.0.0", "legacy"} {
+		require.NoError(t, registry.SaveBuildingBlueprint(ctx, version, registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"}, false))
+		require.NoError(t, registrar.CheckWritable(ctx, version))
+	}
+
+	router := chi.NewRouter()
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}", GetBlueprint())
+	router.Post("/registry/promote/{version}/{state}", PromoteVersion(nil))
+
+	// Names that aren't semantic versions can still be read.
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/registry/blueprint/legacy/building/house", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, withClaims(httptest.NewRequest(http.MethodPost, "/registry/promote/24.0.0/staging", nil), "registry:promote-staging"))
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.ErrorIs(t, registrar.CheckWritable(ctx, "24.0.0"), metadata.ErrFrozen)
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/spf13/viper"
+	"golang.org/x/text/language"
+)
+
+// localesAll is the value of the locales query parameter asking for the text
+// of every locale instead of the best one.
+const localesAll = "all"
+
+// localeRequest is how a request wants display text: in the best of the
+// locales of its Accept-Language header, or all of them.
+type localeRequest struct {
+	accept string
+	all    bool
+}
+
+// requestedLocale reports how r wants display text. Requests with neither an
+// Accept-Language header nor the locales parameter get blueprints as they
+// are stored.
+func requestedLocale(r *http.Request) (localeRequest, bool) {
+	req := localeRequest{
+		accept: r.Header.Get("Accept-Language"),
+		all:    r.URL.Query().Get("locales") == localesAll,
+	}
+
+	return req, req.accept != "" || req.all
+}
+
+// matchLocale picks the available locale that best matches the
+// Accept-Language header, falling back to the default locale. It returns an
+// empty string if neither is available.
+func matchLocale(available []string, accept string) string {
+	if len(available) == 0 {
+		return ""
+	}
+
+	fallback := ""
+	if defaultLocale := viper.GetString(config.FlagDefaultLocale); defaultLocale != "" {
+		for _, tag := range available {
+			if tag == defaultLocale {
+				fallback = tag
+				break
+			}
+		}
+	}
+
+	desired, _, err := language.ParseAcceptLanguage(accept)
+	if err != nil || len(desired) == 0 {
+		return fallback
+	}
+
+	tags := make([]language.Tag, 0, len(available))
+	keys := make([]string, 0, len(available))
+
+	for _, tag := range available {
+		parsed, err := language.Parse(tag)
+		if err != nil {
+			continue
+		}
+
+		tags = append(tags, parsed)
+		keys = append(keys, tag)
+	}
+
+	if len(tags) == 0 {
+		return fallback
+	}
+
+	_, index, confidence := language.NewMatcher(tags).Match(desired...)
+	if confidence == language.No {
+		return fallback
+	}
+
+	return keys[index]
+}
+
+// localeTags returns the sorted tags of locales.
+func localeTags(locales metadata.Locales) []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+
+	return tags
+}
+
+// localize returns bp with the display text of locale, or of every locale
+// with all. Blueprints without text for the locale fall back to the default
+// locale, then to their stored name.
+func localize(bp any, locales metadata.Locales, locale string, all bool) (any, error) {
+	if len(locales) == 0 {
+		return bp, nil
+	}
+
+	display, ok := locales[locale]
+	if !ok && !all {
+		locale = viper.GetString(config.FlagDefaultLocale)
+		if display, ok = locales[locale]; !ok {
+			return bp, nil
+		}
+	}
+
+	fields, err := blueprintFields(bp)
+	if err != nil {
+		return nil, err
+	}
+
+	if all {
+		fields["locales"] = locales
+		return fields, nil
+	}
+
+	if display.Name != "" {
+		setField(fields, "name", display.Name)
+	}
+
+	if display.Description != "" {
+		setField(fields, "description", display.Description)
+	}
+
+	fields["locale"] = locale
+
+	return fields, nil
+}
+
+// setField replaces the field of the given name whatever its case, blueprints
+// read from the registry without JSON tags have capitalized fields.
+func setField(fields map[string]any, name string, value any) {
+	for key := range fields {
+		if strings.EqualFold(key, name) {
+			fields[key] = value
+			return
+		}
+	}
+
+	fields[name] = value
+}
+
+// blueprintLocales reads the display text of a single blueprint.
+func blueprintLocales(ctx context.Context, version, kind, slug string) (metadata.Locales, error) {
+	store, err := metadata.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	annotation, err := store.GetBlueprint(ctx, version, kind, slug)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return annotation.Locales, nil
+}
+
+// versionLocales is the display text of every blueprint of a version.
+type versionLocales struct {
+	byKind map[string]map[string]metadata.Locales
+	tags   []string
+}
+
+func readVersionLocales(ctx context.Context, version string) (*versionLocales, error) {
+	store, err := metadata.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	annotations, err := store.ListBlueprints(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &versionLocales{byKind: make(map[string]map[string]metadata.Locales)}
+	tags := make(metadata.Locales)
+
+	for _, annotation := range annotations {
+		if len(annotation.Locales) == 0 {
+			continue
+		}
+
+		if v.byKind[annotation.Kind] == nil {
+			v.byKind[annotation.Kind] = make(map[string]metadata.Locales)
+		}
+
+		v.byKind[annotation.Kind][annotation.Slug] = annotation.Locales
+
+		for tag := range annotation.Locales {
+			tags[tag] = metadata.Display{}
+		}
+	}
+
+	v.tags = localeTags(tags)
+
+	return v, nil
+}
+
+// variant returns the key of the localized payload a request gets, or an
+// empty string for the stored blueprints.
+func (v *versionLocales) variant(req localeRequest) string {
+	if len(v.tags) == 0 {
+		return ""
+	}
+
+	if req.all {
+		return localesAll
+	}
+
+	return matchLocale(v.tags, req.accept)
+}
+
+// localizeLoaded applies a variant to the loaded blueprints of the version.
+func (v *versionLocales) localizeLoaded(loaded map[string]any, variant string) (map[string]any, error) {
+	localized := make(map[string]any, len(loaded))
+
+	for collection, items := range loaded {
+		kind := collection
+		if k, err := blueprint.Find(collection); err == nil {
+			kind = k.Name
+		}
+
+		raw, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+
+		bySlug := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(raw, &bySlug); err != nil {
+			return nil, err
+		}
+
+		out := make(map[string]any, len(bySlug))
+
+		for slug, bp := range bySlug {
+			item, err := localize(bp, v.byKind[kind][slug], variant, variant == localesAll)
+			if err != nil {
+				return nil, err
+			}
+
+			out[slug] = item
+		}
+
+		localized[collection] = out
+	}
+
+	return localized, nil
+}
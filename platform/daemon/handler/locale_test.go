@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const localeVersion = "17.0.0"
+
+func TestMatchLocale(t *testing.T) {
+	viper.Set(config.FlagDefaultLocale, "en")
+	t.Cleanup(func() {
+		viper.Set(config.FlagDefaultLocale, "")
+	})
+
+	available := []string{"de", "en", "pt-BR"}
+
+	tests := []struct {
+		label    string
+		accept   string
+		expected string
+	}{
+		{label: "exact", accept: "de", expected: "de"},
+		{label: "region", accept: "de-AT, en;q=0.5", expected: "de"},
+		{label: "quality", accept: "de;q=0.5, pt-BR", expected: "pt-BR"},
+		{label: "fallback", accept: "fr", expected: "en"},
+		{label: "empty", accept: "", expected: "en"},
+		{label: "malformed", accept: ";;", expected: "en"},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchLocale(available, tt.accept))
+		}
+
+		t.Run(tt.label, tf)
+	}
+
+	assert.Empty(t, matchLocale([]string{"de"}, "fr"))
+}
+
+func TestLocalizedBlueprints(t *testing.T) {
+	setupStores(t)
+
+	viper.Set(config.FlagDefaultLocale, "en")
+	t.Cleanup(func() {
+		viper.Set(config.FlagDefaultLocale, "")
+	})
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+	router.Get("/registry/blueprint/{version}", GetBlueprints())
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}", GetBlueprint())
+	router.Get("/registry/export/{version}", ExportBlueprints())
+
+	req := httptest.NewRequest(http.MethodPost, "/registry/blueprints", bytes.NewReader([]byte(`{"version": "`+localeVersion+`",
+		"resources": [
+			{"name": "Wood", "slug": "wood", "locales": {"en": {"name": "Wood"}, "de": {"name": "Holz", "description": "Zum Bauen"}}},
+			{"name": "Stone", "slug": "stone"}
+		],
+		"buildings": [
+			{"name": "House", "slug": "house", "build_time": "10s", "cost": [{"resource": "wood", "amount": 10}],
+			 "locales": {"en": {"name": "House", "description": "A place to live"}, "ja": {"name": "家"}}}
+		]}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req = withClaims(req, "registry:write")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	get := func(path, language string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "application/json")
+
+		if language != "" {
+			req.Header.Set("Accept-Language", language)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		return rec
+	}
+
+	decode := func(rec *httptest.ResponseRecorder) map[string]any {
+		fields := make(map[string]any)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fields))
+
+		return lowerKeys(fields)
+	}
+
+	rec = get("/registry/blueprint/"+localeVersion+"/resource/wood", "de-DE, en;q=0.5")
+	assert.Equal(t, "de", rec.Header().Get("Content-Language"))
+
+	wood := decode(rec)
+	assert.Equal(t, "Holz", wood["name"])
+	assert.Equal(t, "Zum Bauen", wood["description"])
+	assert.Equal(t, "wood", wood["slug"])
+
+	rec = get("/registry/blueprint/"+localeVersion+"/building/house", "fr")
+	assert.Equal(t, "en", rec.Header().Get("Content-Language"))
+	assert.Equal(t, "A place to live", decode(rec)["description"])
+
+	rec = get("/registry/blueprint/"+localeVersion+"/resource/stone", "de")
+	assert.Empty(t, rec.Header().Get("Content-Language"))
+	assert.Equal(t, "Stone", decode(rec)["name"])
+
+	rec = get("/registry/blueprint/"+localeVersion+"/resource/wood", "")
+	assert.NotContains(t, decode(rec), "locale")
+
+	rec = get("/registry/blueprint/"+localeVersion+"/building/house?locales=all", "")
+	assert.Equal(t, map[string]any{
+		"en": map[string]any{"name": "House", "description": "A place to live"},
+		"ja": map[string]any{"name": "家"},
+	}, decode(rec)["locales"])
+
+	cluster.SetActive(localeVersion)
+	cache.SetVersion(localeVersion)
+	require.NoError(t, cache.Load(context.Background()))
+
+	t.Cleanup(func() {
+		cluster.SetActive("")
+	})
+
+	var loaded map[string]map[string]map[string]any
+
+	name := func(collection, slug string) any {
+		return lowerKeys(loaded[collection][slug])["name"]
+	}
+
+	rec = get("/registry/blueprint/current", "ja")
+	assert.Equal(t, "ja", rec.Header().Get("Content-Language"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Accept, Accept-Encoding, Accept-Language")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &loaded))
+	assert.Equal(t, "家", name("buildings", "house"))
+	assert.Equal(t, "Wood", name("resources", "wood"))
+	assert.Equal(t, "Stone", name("resources", "stone"))
+
+	localizedTag := rec.Header().Get("ETag")
+
+	rec = get("/registry/blueprint/current", "")
+	assert.NotEqual(t, localizedTag, rec.Header().Get("ETag"))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &loaded))
+	assert.Equal(t, "House", name("buildings", "house"))
+	assert.NotContains(t, loaded["buildings"]["house"], "locale")
+
+	rec = get("/registry/export/"+localeVersion, "")
+
+	var batch struct {
+		Resources []map[string]any `json:"resources"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &batch))
+
+	for _, resource := range batch.Resources {
+		if resource["slug"] == "wood" {
+			assert.Contains(t, resource["locales"], "de")
+		} else {
+			assert.NotContains(t, resource, "locales")
+		}
+	}
+}
+
+// lowerKeys lowercases the fields of a blueprint, the registry's types have no
+// JSON tags.
+func lowerKeys(fields map[string]any) map[string]any {
+	lowered := make(map[string]any, len(fields))
+	for key, value := range fields {
+		lowered[strings.ToLower(key)] = value
+	}
+
+	return lowered
+}
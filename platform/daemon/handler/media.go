@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/go-chi/render"
+)
+
+const (
+	mediaTypeJSON   = "application/json"
+	mediaTypeYAML   = "application/yaml"
+	mediaTypeTarGz  = "application/gzip"
+	mediaTypeXTarGz = "application/x-gtar"
+
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// mediaFormats maps media types without a structured syntax suffix to the
+// format they carry.
+var mediaFormats = map[string]string{
+	mediaTypeJSON:        formatJSON,
+	"text/json":          formatJSON,
+	mediaTypeYAML:        formatYAML,
+	"application/x-yaml": formatYAML,
+	"text/yaml":          formatYAML,
+	"text/x-yaml":        formatYAML,
+}
+
+// mediaFormat returns the format of a bare media type, recognising +json and
+// +yaml suffixes, or an empty string if it isn't JSON or YAML.
+func mediaFormat(mediaType string) string {
+	if format, ok := mediaFormats[mediaType]; ok {
+		return format
+	}
+
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return formatJSON
+	case strings.HasSuffix(mediaType, "+yaml"):
+		return formatYAML
+	default:
+		return ""
+	}
+}
+
+// requestFormat parses a Content-Type header, parameters included, and
+// returns the format of the body.
+func requestFormat(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", NewErrInvalidMediaType(contentType)
+	}
+
+	format := mediaFormat(mediaType)
+	if format == "" {
+		return "", NewErrInvalidMediaType(contentType)
+	}
+
+	return format, nil
+}
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+func (a acceptRange) specificity() int {
+	switch {
+	case a.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(a.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (a acceptRange) matches(offer string) bool {
+	switch {
+	case a.mediaType == "*/*", a.mediaType == offer:
+		return true
+	case strings.HasSuffix(a.mediaType, "/*"):
+		return strings.HasPrefix(offer, strings.TrimSuffix(a.mediaType, "*"))
+	default:
+		format := mediaFormat(a.mediaType)
+
+		return format != "" && format == mediaFormat(offer)
+	}
+}
+
+// parseAccept returns the ranges of an Accept header, most preferred first.
+// Ranges that fail to parse are dropped.
+func parseAccept(accept string) []acceptRange {
+	ranges := make([]acceptRange, 0)
+
+	for _, part := range strings.Split(accept, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+
+	return ranges
+}
+
+// negotiate picks the offer that best matches the Accept header. The first
+// offer is used when the header is empty; an empty string means nothing
+// acceptable was found.
+func negotiate(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+
+	for _, ar := range ranges {
+		if ar.quality <= 0 {
+			continue
+		}
+
+		for _, offer := range offers {
+			if ar.matches(offer) && !excluded(ranges, offer) {
+				return offer
+			}
+		}
+	}
+
+	return ""
+}
+
+// excluded reports whether the offer is explicitly refused with q=0.
+func excluded(ranges []acceptRange, offer string) bool {
+	for _, ar := range ranges {
+		if ar.quality <= 0 && ar.specificity() == 2 && ar.matches(offer) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// respond writes v as JSON or YAML depending on the Accept header.
+func respond(w http.ResponseWriter, r *http.Request, v any) {
+	switch negotiate(r.Header.Get("Accept"), mediaTypeJSON, mediaTypeYAML) {
+	case mediaTypeJSON:
+		render.JSON(w, r, v)
+	case mediaTypeYAML:
+		raw, err := model.ToYAML(v)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", mediaTypeYAML)
+		w.Write(raw) //nolint
+	default:
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+	}
+}
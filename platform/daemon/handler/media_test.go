@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRequestFormat(t *testing.T) {
+	tests := []struct {
+		contentType    string
+		expectedFormat string
+		expectError    bool
+	}{
+		{contentType: "application/json", expectedFormat: formatJSON},
+		{contentType: "application/json; charset=utf-8", expectedFormat: formatJSON},
+		{contentType: "Application/JSON;charset=UTF-8", expectedFormat: formatJSON},
+		{contentType: "application/vnd.avalon.blueprint+json", expectedFormat: formatJSON},
+		{contentType: "application/yaml", expectedFormat: formatYAML},
+		{contentType: "application/x-yaml; charset=utf-8", expectedFormat: formatYAML},
+		{contentType: "text/yaml", expectedFormat: formatYAML},
+		{contentType: "application/vnd.avalon.blueprint+yaml", expectedFormat: formatYAML},
+		{contentType: "text/plain", expectError: true},
+		{contentType: "bogus", expectError: true},
+		{contentType: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			format, err := requestFormat(tt.contentType)
+			if tt.expectError {
+				assert.ErrorAs(t, err, &ErrInvalidMediaType{})
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFormat, format)
+		}
+
+		t.Run(tt.contentType, tf)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{mediaTypeJSON, mediaTypeYAML}
+
+	tests := []struct {
+		label    string
+		accept   string
+		expected string
+	}{
+		{label: "empty", accept: "", expected: mediaTypeJSON},
+		{label: "wildcard", accept: "*/*", expected: mediaTypeJSON},
+		{label: "yaml", accept: "application/yaml", expected: mediaTypeYAML},
+		{label: "x-yaml", accept: "application/x-yaml", expected: mediaTypeYAML},
+		{label: "suffix", accept: "application/vnd.avalon+yaml", expected: mediaTypeYAML},
+		{label: "quality", accept: "application/json;q=0.5, application/yaml", expected: mediaTypeYAML},
+		{label: "specificity", accept: "*/*, application/yaml", expected: mediaTypeYAML},
+		{label: "excluded", accept: "application/json;q=0, */*;q=0.1", expected: mediaTypeYAML},
+		{label: "browser", accept: "text/html,application/xhtml+xml,*/*;q=0.8", expected: mediaTypeJSON},
+		{label: "unacceptable", accept: "text/html", expected: ""},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			assert.Equal(t, tt.expected, negotiate(tt.accept, offers...))
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
+
+func TestGetBlueprintYAML(t *testing.T) {
+	setupStores(t)
+
+	building := registry.BuildingBlueprintRequest{Name: "Farm", Slug: "farm", BuildTime: "30s"}
+	require.NoError(t, registry.SaveBuildingBlueprint(context.Background(), "5.0.0", building, false))
+
+	router := chi.NewRouter()
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}", GetBlueprint())
+
+	req := httptest.NewRequest(http.MethodGet, "/registry/blueprint/5.0.0/building/farm", nil)
+	req.Header.Set("Accept", "application/yaml")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, mediaTypeYAML, rec.Header().Get("Content-Type"))
+
+	var decoded map[string]any
+	require.NoError(t, yaml.Unmarshal(rec.Body.Bytes(), &decoded))
+	assert.Equal(t, "farm", decoded["Slug"])
+	assert.Equal(t, "Farm", decoded["Name"])
+}
@@ -21,7 +21,7 @@ func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
 func (w *ResponseWriter) Write(d []byte) (int, error) {
 	n, err := w.ResponseWriter.Write(d)
 
-	w.Size = n
+	w.Size += n
 
 	return n, err
 }
@@ -35,3 +35,9 @@ func (w *ResponseWriter) WriteHeader(statusCode int) {
 
 	w.ResponseWriter.WriteHeader(statusCode)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController, so
+// handlers can flush streamed responses through the middleware.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const (
+	HeaderBlueprintVersion         = "X-Blueprint-Version"
+	HeaderBlueprintDigest          = "X-Blueprint-Digest"
+	HeaderExpectedBlueprintVersion = "X-Expected-Blueprint-Version"
+)
+
+// ActiveFunc returns the blueprint version the gateway serves and a digest of
+// its content. An empty digest is left out of the response.
+type ActiveFunc func(ctx context.Context) (version, digest string)
+
+// ResolveFunc returns the version a client names, with a v prefix or as an
+// alias like current, in the form ActiveFunc reports versions in.
+type ResolveFunc func(ctx context.Context, version string) (string, error)
+
+// BlueprintVersion tells clients which blueprint version answered the request,
+// so they notice when it changes underneath them.
+func BlueprintVersion(active ActiveFunc) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			version, digest := active(r.Context())
+
+			if version != "" {
+				w.Header().Set(HeaderBlueprintVersion, version)
+			}
+
+			if digest != "" {
+				w.Header().Set(HeaderBlueprintDigest, digest)
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ExpectBlueprintVersion rejects requests of clients that expect another
+// blueprint version than the active one with 409. The expected version is
+// resolved first, requests naming a version that doesn't resolve are rejected
+// with 400. Requests without the header are let through.
+func ExpectBlueprintVersion(active ActiveFunc, resolve ResolveFunc) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			expected := r.Header.Get(HeaderExpectedBlueprintVersion)
+			if expected == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resolved, err := resolve(r.Context(), expected)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid expected blueprint version %s: %s", expected, err), http.StatusBadRequest)
+				return
+			}
+
+			if version, _ := active(r.Context()); resolved != version {
+				msg := fmt.Sprintf("blueprint version changed: expected %s, active is %s", expected, version)
+				http.Error(w, msg, http.StatusConflict)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
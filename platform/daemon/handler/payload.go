@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	encodingIdentity = "identity"
+	encodingGzip     = "gzip"
+	encodingZstd     = "zstd"
+
+	cacheControlImmutable   = "public, max-age=31536000, immutable"
+	cacheControlRevalidated = "no-cache"
+)
+
+// payloadEncodings are the precompressed variants, in the order the server
+// prefers them.
+var payloadEncodings = []string{encodingZstd, encodingGzip}
+
+// payload is a version of the loaded blueprints serialized once, together with
+// precompressed variants and the hash of its content.
+type payload struct {
+	version string
+	locale  string
+	hash    string
+	frozen  bool
+	bodies  map[string][]byte
+}
+
+func newPayload(version string, v any, frozen bool) (*payload, error) {
+	// Encoded like render.JSON, clients see the same bytes as before.
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	raw := buf.Bytes()
+	sum := sha256.Sum256(raw)
+
+	p := &payload{
+		version: version,
+		hash:    hex.EncodeToString(sum[:]),
+		frozen:  frozen,
+		bodies:  map[string][]byte{encodingIdentity: raw},
+	}
+
+	gz := &bytes.Buffer{}
+	gw, err := gzip.NewWriterLevel(gz, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := gw.Write(raw); err != nil {
+		return nil, err
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	p.bodies[encodingGzip] = gz.Bytes()
+
+	zw, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	if err != nil {
+		return nil, err
+	}
+
+	p.bodies[encodingZstd] = zw.EncodeAll(raw, nil)
+
+	return p, zw.Close()
+}
+
+// digest identifies the content of the payload, whatever the encoding.
+func (p *payload) digest() string {
+	return "sha256:" + p.hash
+}
+
+// etag returns the strong entity tag of a variant. Variants share the hash and
+// differ in suffix, as their bytes differ.
+func (p *payload) etag(encoding string) string {
+	if encoding == encodingIdentity {
+		return strconv.Quote(p.hash)
+	}
+
+	return strconv.Quote(p.hash + "-" + encoding)
+}
+
+// notModified reports whether an If-None-Match header matches any variant of
+// the payload.
+func (p *payload) notModified(header string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+
+		if tag == "*" {
+			return true
+		}
+
+		tag = strings.Trim(tag, `"`)
+		if tag == p.hash || strings.HasPrefix(tag, p.hash+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serve writes the variant the client accepts. Explicit version URLs of
+// frozen versions never change and are cached for good; everything else has
+// to be revalidated.
+func (p *payload) serve(w http.ResponseWriter, r *http.Request, explicit bool) {
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+	if encoding == "" {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	}
+
+	header := w.Header()
+	header.Set("ETag", p.etag(encoding))
+	header.Add("Vary", "Accept, Accept-Encoding, Accept-Language")
+
+	if p.locale != "" && p.locale != localesAll {
+		header.Set("Content-Language", p.locale)
+	}
+
+	if explicit && p.frozen {
+		header.Set("Cache-Control", cacheControlImmutable)
+	} else {
+		header.Set("Cache-Control", cacheControlRevalidated)
+	}
+
+	if p.notModified(r.Header.Get("If-None-Match")) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body := p.bodies[encoding]
+
+	header.Set("Content-Type", mediaTypeJSON)
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	if encoding != encodingIdentity {
+		header.Set("Content-Encoding", encoding)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method != http.MethodHead {
+		w.Write(body) //nolint
+	}
+}
+
+// negotiateEncoding picks a precompressed variant the Accept-Encoding header
+// allows, falling back to identity. An empty string means identity is refused
+// too.
+func negotiateEncoding(header string) string {
+	qualities := make(map[string]float64)
+
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		qualities[coding] = quality
+	}
+
+	accepts := func(coding string) (float64, bool) {
+		if q, ok := qualities[coding]; ok {
+			return q, true
+		}
+
+		q, ok := qualities["*"]
+
+		return q, ok
+	}
+
+	best, bestQuality := "", 0.0
+
+	for _, coding := range payloadEncodings {
+		if q, ok := accepts(coding); ok && q > bestQuality {
+			best, bestQuality = coding, q
+		}
+	}
+
+	if best != "" {
+		return best
+	}
+
+	if q, ok := accepts(encodingIdentity); ok && q <= 0 {
+		return ""
+	}
+
+	return encodingIdentity
+}
+
+type payloadStore struct {
+	mx      *sync.Mutex
+	current *payload
+
+	// locales and localized belong to the current payload, the localized
+	// variants are built on first use.
+	locales   *versionLocales
+	localized map[string]*payload
+}
+
+// payloads holds the serialized active version. It is rebuilt after every load
+// of the cache, and on first use after startup.
+var payloads = &payloadStore{mx: &sync.Mutex{}}
+
+// get returns the payload of the active version, building it if the version
+// changed.
+func (s *payloadStore) get(ctx context.Context, version string) (*payload, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.current != nil && s.current.version == version {
+		return s.current, nil
+	}
+
+	return s.build(ctx, version)
+}
+
+func (s *payloadStore) build(ctx context.Context, version string) (*payload, error) {
+	// Until it is known to be frozen, the version is revalidated. A promotion
+	// marks it frozen later on.
+	frozen, err := isFrozen(ctx, version)
+	if err != nil {
+		slog.Warn("failed to look up version state, serving it as a draft", "error", err, "version", version)
+	}
+
+	p, err := newPayload(version, loadedBlueprints(ctx, version), frozen)
+	if err != nil {
+		return nil, err
+	}
+
+	s.current = p
+	s.locales = nil
+	s.localized = make(map[string]*payload)
+
+	return p, nil
+}
+
+// getLocalized returns the payload of the active version in the locale req
+// asks for. Versions without display text have a single payload.
+func (s *payloadStore) getLocalized(ctx context.Context, version string, req localeRequest) (*payload, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	current := s.current
+	if current == nil || current.version != version {
+		var err error
+		if current, err = s.build(ctx, version); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.locales == nil {
+		locales, err := readVersionLocales(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+
+		s.locales = locales
+	}
+
+	variant := s.locales.variant(req)
+	if variant == "" {
+		return current, nil
+	}
+
+	if p, ok := s.localized[variant]; ok {
+		return p, nil
+	}
+
+	localized, err := s.locales.localizeLoaded(loadedBlueprints(ctx, version), variant)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := newPayload(version, localized, current.frozen)
+	if err != nil {
+		return nil, err
+	}
+
+	p.locale = variant
+	s.localized[variant] = p
+
+	return p, nil
+}
+
+// promoted marks the payloads of version frozen, once it has been promoted.
+func (s *payloadStore) promoted(version string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.current == nil || s.current.version != version {
+		return
+	}
+
+	// Payloads are shared with requests being served, they're replaced
+	// rather than changed.
+	s.current = s.current.frozenCopy()
+
+	for variant, p := range s.localized {
+		s.localized[variant] = p.frozenCopy()
+	}
+}
+
+func (p *payload) frozenCopy() *payload {
+	frozen := *p
+	frozen.frozen = true
+
+	return &frozen
+}
+
+// preparePayload serializes a freshly loaded version.
+func preparePayload(ctx context.Context, version string) {
+	payloads.mx.Lock()
+	defer payloads.mx.Unlock()
+
+	if _, err := payloads.build(ctx, version); err != nil {
+		slog.Error("failed to serialize blueprints", "error", err, "version", version)
+	}
+}
+
+// activeBlueprints returns the active version and the digest of its payload.
+func activeBlueprints(ctx context.Context) (string, string) {
+	version := cluster.Active()
+	if version == "" {
+		return "", ""
+	}
+
+	p, err := payloads.get(ctx, version)
+	if err != nil {
+		slog.Error("failed to serialize blueprints", "error", err, "version", version)
+		return version, ""
+	}
+
+	return version, p.digest()
+}
+
+// expectedVersion resolves the version a client expects to be active like the
+// versions of routes, with or without a v prefix and by alias.
+func expectedVersion(ctx context.Context, version string) (string, error) {
+	return resolveVersion(ctx, version, "", false)
+}
+
+// loadedBlueprints returns the active version as loaded in the cache, or the
+// stale snapshot while the registry is unreachable.
+func loadedBlueprints(ctx context.Context, version string) map[string]any {
+	if set, ok := staleVersion(version); ok {
+		return set.Loaded()
+	}
+
+	return cache.GetLoadedBlueprints(ctx)
+}
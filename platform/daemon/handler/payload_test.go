@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/handler/middleware"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/snapshot"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
+	"github.com/go-chi/chi/v5"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		label    string
+		header   string
+		expected string
+	}{
+		{label: "empty", header: "", expected: encodingIdentity},
+		{label: "gzip", header: "gzip, deflate", expected: encodingGzip},
+		{label: "zstd preferred", header: "gzip, deflate, br, zstd", expected: encodingZstd},
+		{label: "quality", header: "zstd;q=0.5, gzip", expected: encodingGzip},
+		{label: "refused", header: "zstd;q=0, gzip;q=0", expected: encodingIdentity},
+		{label: "wildcard", header: "*", expected: encodingZstd},
+		{label: "wildcard refused", header: "gzip;q=0, *;q=0.1", expected: encodingZstd},
+		{label: "nothing acceptable", header: "br, identity;q=0", expected: ""},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			assert.Equal(t, tt.expected, negotiateEncoding(tt.header))
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
+
+func TestGetBlueprintsPayload(t *testing.T) {
+	setupStores(t)
+
+	const version = "11.0.0"
+
+	ctx := context.Background()
+
+	store, err := metadata.Get()
+	require.NoError(t, err)
+
+	v := metadata.NewVersion(version)
+	require.NoError(t, v.Promote(metadata.StateStaging, "test"))
+	require.NoError(t, store.SaveVersion(ctx, v))
+
+	require.NoError(t, registry.SaveBuildingBlueprint(ctx, version, registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"}, false))
+	require.NoError(t, registry.SaveResourceBlueprint(ctx, version, registry.ResourceBlueprintRequest{Name: "Wood", Slug: "wood"}, false))
+
+	cluster.SetActive(version)
+	cache.SetVersion(version)
+	require.NoError(t, cache.Load(ctx))
+
+	t.Cleanup(func() {
+		cluster.SetActive("")
+	})
+
+	router := chi.NewRouter()
+	router.Get("/registry/blueprint/{version}", GetBlueprints())
+
+	get := func(version string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/registry/blueprint/"+version, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := get("current", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, cacheControlRevalidated, rec.Header().Get("Cache-Control"))
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	identity := rec.Body.Bytes()
+
+	var loaded map[string]map[string]any
+	require.NoError(t, json.Unmarshal(identity, &loaded))
+	assert.Contains(t, loaded["buildings"], "house")
+
+	rec = get(version, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, cacheControlImmutable, rec.Header().Get("Cache-Control"))
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+
+	rec = get(version, map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.Bytes())
+
+	rec = get("current", map[string]string{"If-None-Match": `"stale", ` + etag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = get("current", map[string]string{"Accept-Encoding": "gzip"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, encodingGzip, rec.Header().Get("Content-Encoding"))
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+
+	gr, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, identity, raw)
+
+	gzipTag := rec.Header().Get("ETag")
+	rec = get("current", map[string]string{"Accept-Encoding": "gzip", "If-None-Match": gzipTag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = get("current", map[string]string{"Accept-Encoding": "zstd"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, encodingZstd, rec.Header().Get("Content-Encoding"))
+
+	zr, err := zstd.NewReader(rec.Body)
+	require.NoError(t, err)
+	defer zr.Close()
+	raw, err = io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, identity, raw)
+
+	// A reload of the same version is serialized again.
+	require.NoError(t, registry.SaveBuildingBlueprint(ctx, version, registry.BuildingBlueprintRequest{Name: "Hut", Slug: "hut", BuildTime: "5s"}, false))
+	require.NoError(t, cache.Load(ctx))
+	preparePayload(ctx, version)
+
+	rec = get("current", map[string]string{"If-None-Match": etag})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	assert.True(t, bytes.Contains(rec.Body.Bytes(), []byte(`"hut"`)))
+
+	rec = get("9.9.9", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPayloadPromoted(t *testing.T) {
+	setupStores(t)
+
+	const version = "25.0.0"
+
+	ctx := context.Background()
+
+	store, err := metadata.Get()
+	require.NoError(t, err)
+	require.NoError(t, store.SaveVersion(ctx, metadata.NewVersion(version)))
+
+	require.NoError(t, registry.SaveBuildingBlueprint(ctx, version, registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"}, false))
+	require.NoError(t, registry.SaveResourceBlueprint(ctx, version, registry.ResourceBlueprintRequest{Name: "Wood", Slug: "wood"}, false))
+
+	cluster.SetActive(version)
+	cache.SetVersion(version)
+	require.NoError(t, cache.Load(ctx))
+	preparePayload(ctx, version)
+
+	t.Cleanup(func() {
+		cluster.SetActive("")
+	})
+
+	router := chi.NewRouter()
+	router.Get("/registry/blueprint/{version}", GetBlueprints())
+	router.Post("/registry/promote/{version}/{state}", PromoteVersion(nil))
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/registry/blueprint/"+version, nil))
+
+		return rec
+	}
+
+	rec := get()
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, cacheControlRevalidated, rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, withClaims(httptest.NewRequest(http.MethodPost, "/registry/promote/"+version+"/staging", nil), "registry:promote-staging"))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = get()
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, cacheControlImmutable, rec.Header().Get("Cache-Control"))
+}
+
+func TestBlueprintVersionHeaders(t *testing.T) {
+	const version = "12.0.0"
+
+	cluster.SetActive(version)
+	t.Cleanup(func() {
+		cluster.SetActive("")
+		snapshot.Recover()
+	})
+
+	snapshot.Serve(&snapshot.Snapshot{
+		Version: version,
+		Blueprints: blueprint.Set{
+			blueprint.KindBuilding: {&proto.BuildingBlueprint{Name: "House", Slug: "house"}},
+		},
+	}, errors.New("connection refused"))
+
+	router := chi.NewRouter()
+	router.Use(middleware.BlueprintVersion(activeBlueprints))
+	router.Get("/registry/blueprint/{version}", GetBlueprints())
+	router.Group(func(rr chi.Router) {
+		rr.Use(middleware.ExpectBlueprintVersion(activeBlueprints, expectedVersion))
+		rr.Post("/build", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+	})
+
+	build := func(expected string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/build", nil)
+		if expected != "" {
+			req.Header.Set(middleware.HeaderExpectedBlueprintVersion, expected)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/registry/blueprint/current", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, version, rec.Header().Get(middleware.HeaderBlueprintVersion))
+
+	digest := rec.Header().Get(middleware.HeaderBlueprintDigest)
+	assert.Equal(t, "sha256:"+strings.Trim(rec.Header().Get("ETag"), `"`), digest)
+
+	tests := []struct {
+		label    string
+		expected string
+		status   int
+	}{
+		{label: "no header", expected: "", status: http.StatusAccepted},
+		{label: "active version", expected: version, status: http.StatusAccepted},
+		{label: "v prefix", expected: "v" + version, status: http.StatusAccepted},
+		{label: "alias", expected: "current", status: http.StatusAccepted},
+		{label: "stale client", expected: "11.0.0", status: http.StatusConflict},
+		{label: "stale client with v prefix", expected: "v11.0.0", status: http.StatusConflict},
+		{label: "invalid", expected: "next", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			rec := build(tt.expected)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, version, rec.Header().Get(middleware.HeaderBlueprintVersion))
+			assert.Equal(t, digest, rec.Header().Get(middleware.HeaderBlueprintDigest))
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
@@ -2,19 +2,20 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"strings"
 
-	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
 	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
-	"github.com/GnarloqGames/genesis-avalon-kit/registry"
-	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/registrar"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
-	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
@@ -32,21 +33,71 @@ func NewErrInvalidMediaType(t string) ErrInvalidMediaType {
 	}
 }
 
+// GetBlueprints serves the active version, serialized once per load. JSON is
+// served with an ETag and precompressed variants, YAML is encoded per request.
 func GetBlueprints() http.HandlerFunc {
+	logger := slog.Default().With("context", "GetBlueprints")
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		version := chi.URLParam(r, "version")
+		requested := strings.TrimPrefix(chi.URLParam(r, "version"), "v")
 
-		var store map[string]any
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
 
-		switch version {
-		case "current", viper.GetString(config.FlagBlueprintVersion):
-			store = cache.GetLoadedBlueprints(r.Context())
-		default:
+		if !isActiveVersion(version) {
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 			return
 		}
 
-		render.JSON(w, r, store)
+		locale, localized := requestedLocale(r)
+
+		if negotiate(r.Header.Get("Accept"), mediaTypeJSON, mediaTypeYAML) != mediaTypeJSON {
+			loaded := loadedBlueprints(r.Context(), version)
+
+			if localized {
+				locales, err := readVersionLocales(r.Context(), version)
+				if err != nil {
+					logger.Error("failed to read blueprint locales", "error", err, "version", version)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+					return
+				}
+
+				if variant := locales.variant(locale); variant != "" {
+					if loaded, err = locales.localizeLoaded(loaded, variant); err != nil {
+						logger.Error("failed to localize blueprints", "error", err, "version", version)
+						http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+						return
+					}
+				}
+			}
+
+			respond(w, r, loaded)
+
+			return
+		}
+
+		var (
+			p   *payload
+			err error
+		)
+
+		if localized {
+			p, err = payloads.getLocalized(r.Context(), version, locale)
+		} else {
+			p, err = payloads.get(r.Context(), version)
+		}
+
+		if err != nil {
+			logger.Error("failed to serialize blueprints", "error", err, "version", version)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		p.serve(w, r, requested == version)
 	}
 
 	return http.HandlerFunc(fn)
@@ -54,103 +105,225 @@ func GetBlueprints() http.HandlerFunc {
 
 func GetBlueprint() http.HandlerFunc {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		version := chi.URLParam(r, "version")
-		kind := chi.URLParam(r, "kind")
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
 		slug := chi.URLParam(r, "slug")
 
-		version = strings.TrimPrefix(version, "v")
+		kind, err := blueprint.Get(chi.URLParam(r, "kind"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
 
-		// If we're trying to get currently deployed version, try cache first
-		if version == "current" || version == viper.GetString(config.FlagBlueprintVersion) {
-			var (
-				bp any
-				ok bool
-			)
-
-			switch kind {
-			case "building":
-				bp, ok = cache.GetBuildingBlueprint(r.Context(), slug)
-			case "resource":
-				bp, ok = cache.GetResourceBlueprint(r.Context(), slug)
-			}
+		// While the registry is unreachable the active version is served from
+		// the snapshot.
+		if set, ok := staleVersion(version); ok && respondFromSet(w, r, set, kind.Name, slug) {
+			return
+		}
 
+		// If we're trying to get currently deployed version, try cache first
+		if isActiveVersion(version) && kind.Cached != nil {
+			bp, ok := kind.Cached(r.Context(), slug)
 			if !ok {
 				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 				return
 			}
 
-			render.JSON(w, r, bp)
+			respondBlueprint(w, r, version, kind.Name, slug, bp)
 
 			return
 		}
 
-		switch kind {
-		case "building":
-			bp, err := registry.GetBuildingBlueprint(r.Context(), version, slug)
-			if err != nil {
-				slog.Debug("failed to get building blueprint", "version", version, "slug", slug)
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		// Promoted versions are served from the version cache.
+		if set, ok, err := cachedVersion(r.Context(), version); err == nil && ok && respondFromSet(w, r, set, kind.Name, slug) {
+			return
+		}
 
-				return
-			}
+		bp, err := kind.Lookup(r.Context(), version, slug)
+		if err != nil {
+			slog.Debug("failed to get blueprint", "kind", kind.Name, "version", version, "slug", slug)
 
-			render.JSON(w, r, bp)
-		case "resource":
-			bp, err := registry.GetResourceBlueprint(r.Context(), version, slug)
-			if err != nil {
-				slog.Debug("failed to get resource blueprint", "version", version, "slug", slug)
+			if errors.Is(err, blueprint.ErrNotFound) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-
-				return
 			}
 
-			render.JSON(w, r, bp)
+			return
 		}
+
+		respondBlueprint(w, r, version, kind.Name, slug, bp)
 	}
 
 	return fn
 }
 
+// respondFromSet writes the blueprint from set, or 404 if the set doesn't have
+// it. It reports false for kinds the set doesn't hold.
+func respondFromSet(w http.ResponseWriter, r *http.Request, set *versionSet, kind, slug string) bool {
+	bp, found, held := set.find(kind, slug)
+	if !held {
+		return false
+	}
+
+	if !found {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return true
+	}
+
+	respondBlueprint(w, r, set.Version, kind, slug, bp)
+
+	return true
+}
+
+// respondBlueprint writes bp with the URLs of its assets, in the locale the
+// request asks for.
+func respondBlueprint(w http.ResponseWriter, r *http.Request, version, kind, slug string, bp any) {
+	assets, err := blueprintAssets(r.Context(), version, kind, slug)
+	if err != nil {
+		slog.Warn("failed to list blueprint assets", "error", err, "kind", kind, "version", version, "slug", slug)
+	}
+
+	if req, ok := requestedLocale(r); ok {
+		locales, err := blueprintLocales(r.Context(), version, kind, slug)
+		if err != nil {
+			slog.Error("failed to read blueprint locales", "error", err, "kind", kind, "version", version, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Language")
+
+		if bp, err = localize(bp, locales, matchLocale(localeTags(locales), req.accept), req.all); err != nil {
+			slog.Error("failed to localize blueprint", "error", err, "kind", kind, "version", version, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if fields, ok := bp.(map[string]any); ok && !req.all {
+			if tag, ok := fields["locale"].(string); ok {
+				w.Header().Set("Content-Language", tag)
+			}
+		}
+	}
+
+	if len(assets) > 0 {
+		fields, err := blueprintFields(bp)
+		if err != nil {
+			slog.Error("failed to add blueprint assets", "error", err, "kind", kind, "version", version, "slug", slug)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		fields["assets"] = assets
+		bp = fields
+	}
+
+	respond(w, r, bp)
+}
+
+// blueprintFields returns bp as a map of its JSON fields, so fields the
+// registry doesn't know about can be added.
+func blueprintFields(bp any) (map[string]any, error) {
+	if fields, ok := bp.(map[string]any); ok {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(bp)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]any)
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
 func AddBlueprintBatch() http.HandlerFunc {
+	logger := slog.Default().With("context", "AddBlueprintBatch")
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		req, err := decodeRequest[*model.BlueprintBatchRequest](r)
+		claims, ok := authorize(w, r, logger, "dev.avalon.cool:registry:write")
+		if !ok {
+			return
+		}
+
+		var (
+			req *model.BlueprintBatchRequest
+			err error
+		)
+
+		if isCSVUpload(r.Header.Get("Content-Type")) {
+			req, err = decodeCSVBatch(r)
+		} else {
+			req, err = decodeRequest[*model.BlueprintBatchRequest](r)
+		}
 
 		if err != nil {
 			slog.Error("failed to decode blueprint request", "error", err)
 
-			if _, ok := err.(ErrInvalidMediaType); ok {
-				http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
-			} else {
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			var csvErr *csvUploadError
+			if errors.As(err, &csvErr) {
+				writeCSVError(w, r, csvErr)
+				return
 			}
 
+			writeDecodeError(w, err)
+
 			return
 		}
 
-		for _, building := range req.Buildings {
-			if err := registry.SaveBuildingBlueprint(r.Context(), req.Version, building, req.Force); err != nil {
-				slog.Error("failed to save blueprint",
-					"error", err,
-					"kind", "building",
-					"name", building.Name)
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		if err := registrar.CheckWritable(r.Context(), req.Version); err != nil {
+			slog.Error("version is not writable", "error", err, "version", req.Version)
+			writeVersionError(w, err)
+
+			return
+		}
+
+		if err := req.Resolve(registrar.Base(r.Context(), req.Version)); err != nil {
+			slog.Debug("failed to resolve blueprint templates", "error", err, "version", req.Version)
+			writeDecodeError(w, err)
+
+			return
+		}
+
+		requests := req.Requests()
+
+		for i := range requests {
+			if err := registrar.Validate(&requests[i]); err != nil {
+				slog.Debug("invalid blueprint", "error", err, "kind", requests[i].Kind)
+				http.Error(w, err.Error(), http.StatusBadRequest)
 
 				return
 			}
 		}
 
-		for _, resource := range req.Resources {
-			if err := registry.SaveResourceBlueprint(r.Context(), req.Version, resource, req.Force); err != nil {
+		trail := registrar.NewTrail(claims.Subject)
+
+		for i := range requests {
+			if err := registrar.SaveBlueprint(r.Context(), &requests[i], trail); err != nil {
 				slog.Error("failed to save blueprint",
 					"error", err,
-					"kind", "resource",
-					"name", resource.Name)
+					"kind", requests[i].Kind,
+					"name", requests[i].Definition.GetName())
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 
 				return
 			}
 		}
 
+		event := webhook.NewEvent(webhook.EventVersionWritten, req.Version)
+		event.Subject = claims.Subject
+		event.Count = len(requests)
+		webhook.Publish(event)
+
 		render.JSON(w, r, map[string]string{
 			"status": "OK",
 		})
@@ -160,43 +333,44 @@ func AddBlueprintBatch() http.HandlerFunc {
 }
 
 func AddBlueprint() http.HandlerFunc {
+	logger := slog.Default().With("context", "AddBlueprint")
 	fn := func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authorize(w, r, logger, "dev.avalon.cool:registry:write")
+		if !ok {
+			return
+		}
+
 		req, err := decodeRequest[*model.BlueprintRequest](r)
 		if err != nil {
 			slog.Error("failed to decode blueprint request", "error", err)
-
-			if _, ok := err.(ErrInvalidMediaType); ok {
-				http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
-			} else {
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-			}
+			writeDecodeError(w, err)
 
 			return
 		}
 
-		var insertErr error
+		if err := registrar.Resolve(r.Context(), req); err != nil {
+			slog.Debug("failed to resolve blueprint template", "error", err, "kind", req.Kind)
+			writeDecodeError(w, err)
 
-		switch req.Kind {
-		case model.KindBuilding:
-			def := req.Definition.(registry.BuildingBlueprintRequest)
-			insertErr = registry.SaveBuildingBlueprint(r.Context(), req.Version, def, false)
-		case model.KindResource:
-			def := req.Definition.(registry.ResourceBlueprintRequest)
-			insertErr = registry.SaveResourceBlueprint(r.Context(), req.Version, def, false)
-		case "":
-			slog.Info("error: missing kind field")
+			return
+		}
+
+		if err := registrar.Validate(req); err != nil {
+			slog.Debug("error: invalid blueprint", "error", err, "kind", req.Kind)
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 
 			return
-		default:
-			slog.Debug("error: invalid kind field", "kind", req.Kind)
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		}
+
+		if err := registrar.CheckWritable(r.Context(), req.Version); err != nil {
+			slog.Error("version is not writable", "error", err, "version", req.Version)
+			writeVersionError(w, err)
 
 			return
 		}
 
-		if insertErr != nil {
-			slog.Error("failed to insert blueprint", "error", insertErr, "kind", req.Kind)
+		if err := registrar.SaveBlueprint(r.Context(), req, registrar.NewTrail(claims.Subject)); err != nil {
+			slog.Error("failed to insert blueprint", "error", err, "kind", req.Kind)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 
 			return
@@ -208,12 +382,46 @@ func AddBlueprint() http.HandlerFunc {
 	return http.HandlerFunc(fn)
 }
 
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var (
+		schemaErr blueprint.SchemaError
+		formErr   errCSVForm
+	)
+
+	switch {
+	case errors.As(err, &ErrInvalidMediaType{}):
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+	case errors.As(err, &schemaErr), errors.As(err, &formErr), errors.Is(err, blueprint.ErrUnknownKind),
+		errors.Is(err, model.ErrInheritanceCycle), errors.Is(err, model.ErrBaseNotFound), errors.Is(err, model.ErrInvalidTemplate),
+		errors.Is(err, registrar.ErrMissingKind), errors.Is(err, registrar.ErrInvalidKind):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func writeVersionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, metadata.ErrFrozen):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, metadata.ErrInvalidVersion):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
 type RequestInto interface {
 	*model.BlueprintRequest
 }
 
-func decodeRequest[T *model.BlueprintRequest | *model.BlueprintBatchRequest](r *http.Request) (T, error) {
-	contentType := r.Header.Get("Content-Type")
+func decodeRequest[T *model.BlueprintRequest | *model.BlueprintBatchRequest | *model.SimulationRequest | *model.BuildRequest](r *http.Request) (T, error) {
+	format, err := requestFormat(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -223,13 +431,11 @@ func decodeRequest[T *model.BlueprintRequest | *model.BlueprintBatchRequest](r *
 
 	var rr T
 
-	switch contentType {
-	case "application/json":
+	switch format {
+	case formatJSON:
 		err = json.Unmarshal(body, &rr)
-	case "application/yaml":
+	case formatYAML:
 		err = yaml.Unmarshal(body, &rr)
-	default:
-		return nil, NewErrInvalidMediaType(contentType)
 	}
 
 	if err != nil {
@@ -25,7 +25,9 @@ var (
 	buildingBody = model.BlueprintRequest{
 		Kind: "building",
 		Definition: registry.BuildingBlueprintRequest{
-			Name: "house",
+			Name:      "house",
+			Slug:      "house",
+			BuildTime: "10s",
 		},
 	}
 
@@ -33,7 +35,9 @@ var (
 		Kind:    "building",
 		Version: errVersion,
 		Definition: registry.BuildingBlueprintRequest{
-			Name: "house",
+			Name:      "house",
+			Slug:      "house",
+			BuildTime: "10s",
 		},
 	}
 
@@ -41,19 +45,24 @@ var (
 		Kind: "resource",
 		Definition: registry.ResourceBlueprintRequest{
 			Name: "wood",
+			Slug: "wood",
 		},
 	}
 
 	invalidKind = model.BlueprintRequest{
 		Kind: "bogus",
 		Definition: registry.BuildingBlueprintRequest{
-			Name: "house",
+			Name:      "house",
+			Slug:      "house",
+			BuildTime: "10s",
 		},
 	}
 
 	missingKind = model.BlueprintRequest{
 		Definition: registry.BuildingBlueprintRequest{
-			Name: "house",
+			Name:      "house",
+			Slug:      "house",
+			BuildTime: "10s",
 		},
 	}
 
@@ -61,6 +70,8 @@ var (
 kind: building
 body:
     name: house
+    slug: house
+    build_time: 10s
 `)
 )
 
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/go-chi/chi/v5"
+)
+
+const mediaTypeSchemaJSON = "application/schema+json"
+
+// GetSchema publishes the JSON Schema of a blueprint kind. Uploads are
+// validated against the same schema.
+func GetSchema() http.HandlerFunc {
+	logger := slog.Default().With("context", "GetSchema")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		kind, err := blueprint.Get(chi.URLParam(r, "kind"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		schema, err := kind.Schema()
+		if err != nil {
+			logger.Error("failed to build schema", "error", err, "kind", kind.Name)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if schema == nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", mediaTypeSchemaJSON)
+		w.Write(schema) //nolint
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSchema(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/registry/schema/{kind}", GetSchema())
+
+	tests := []struct {
+		kind           string
+		expectedStatus int
+	}{
+		{kind: "building", expectedStatus: http.StatusOK},
+		{kind: "resource", expectedStatus: http.StatusOK},
+		{kind: "bogus", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/registry/schema/"+tt.kind, nil))
+
+			require.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, mediaTypeSchemaJSON, rec.Header().Get("Content-Type"))
+
+			var schema map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &schema))
+			assert.Equal(t, map[string]any{"const": tt.kind}, schema["properties"].(map[string]any)["kind"])
+		}
+
+		t.Run(tt.kind, tf)
+	}
+}
+
+func TestSchemaDocuments(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/registry/schema/{kind}", GetSchema())
+
+	tests := []struct {
+		label       string
+		kind        string
+		document    string
+		expectError bool
+	}{
+		{label: "blueprint", kind: "building", document: `{"kind":"building","version":"1.0.0","body":{"name":"House","slug":"house","build_time":"10s"}}`},
+		{label: "extends", kind: "building", document: `{"kind":"building","version":"1.0.0","extends":"house","body":{"name":"Big House","slug":"big-house"}}`},
+		{label: "extends without body", kind: "resource", document: `{"kind":"resource","extends":"wood"}`},
+		{label: "locales", kind: "resource", document: `{"kind":"resource","body":{"name":"Wood","slug":"wood"},"locales":{"en":{"name":"Wood"},"de":{"name":"Holz","description":"Zum Bauen"}}}`},
+		{label: "extends with locales", kind: "building", document: `{"kind":"building","extends":"house","body":{"slug":"big-house"},"locales":{"de":{"name":"Großes Haus"}}}`},
+		{label: "unknown display field", kind: "resource", document: `{"kind":"resource","body":{"name":"Wood","slug":"wood"},"locales":{"en":{"title":"Wood"}}}`, expectError: true},
+		{label: "invalid locales", kind: "resource", document: `{"kind":"resource","body":{"name":"Wood","slug":"wood"},"locales":["en"]}`, expectError: true},
+		{label: "missing body", kind: "building", document: `{"kind":"building","version":"1.0.0"}`, expectError: true},
+		{label: "incomplete body", kind: "building", document: `{"kind":"building","body":{"name":"House","slug":"house"}}`, expectError: true},
+		{label: "empty extends", kind: "building", document: `{"kind":"building","extends":"","body":{"name":"Big House"}}`, expectError: true},
+		{label: "unknown override", kind: "resource", document: `{"kind":"resource","extends":"wood","body":{"weight":2}}`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/registry/schema/"+tt.kind, nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			compiler := jsonschema.NewCompiler()
+			compiler.Draft = jsonschema.Draft7
+			require.NoError(t, compiler.AddResource("schema.json", rec.Body))
+
+			schema, err := compiler.Compile("schema.json")
+			require.NoError(t, err)
+
+			var document any
+			require.NoError(t, json.Unmarshal([]byte(tt.document), &document))
+
+			err = schema.Validate(document)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
+
+func TestAddBlueprintSchema(t *testing.T) {
+	setupStores(t)
+
+	router := chi.NewRouter()
+	router.Post("/registry/blueprint", AddBlueprint())
+	router.Post("/registry/blueprints", AddBlueprintBatch())
+
+	tests := []struct {
+		label          string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			label:          "valid",
+			path:           "/registry/blueprint",
+			body:           `{"kind":"building","version":"9.0.0","body":{"name":"House","slug":"house","build_time":"10s"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			label:          "invalid duration",
+			path:           "/registry/blueprint",
+			body:           `{"kind":"building","version":"9.0.0","body":{"name":"House","slug":"house","build_time":"soon"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			label:          "unknown field",
+			path:           "/registry/blueprint",
+			body:           `{"kind":"resource","version":"9.0.0","body":{"name":"Wood","slug":"wood","weight":2}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			label:          "batch",
+			path:           "/registry/blueprints",
+			body:           `{"version":"9.0.0","resources":[{"name":"Wood"}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			req = withClaims(req, "registry:write")
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
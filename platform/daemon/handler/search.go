@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+)
+
+const (
+	searchDefaultLimit = 50
+	searchMaxLimit     = 500
+)
+
+type searchQuery struct {
+	Kind         string
+	Name         string
+	MinBuildTime time.Duration
+	MaxBuildTime time.Duration
+	CostResource string
+	Produces     string
+	Offset       int
+	Limit        int
+}
+
+type searchResult struct {
+	Kind      string `json:"kind"`
+	Blueprint any    `json:"blueprint"`
+}
+
+type searchResponse struct {
+	Version string         `json:"version"`
+	Total   int            `json:"total"`
+	Offset  int            `json:"offset"`
+	Limit   int            `json:"limit"`
+	Items   []searchResult `json:"items"`
+}
+
+func parseSearchQuery(values url.Values) (*searchQuery, error) {
+	q := &searchQuery{
+		Kind:         values.Get("kind"),
+		Name:         strings.ToLower(values.Get("name")),
+		CostResource: values.Get("cost"),
+		Produces:     values.Get("produces"),
+		Limit:        searchDefaultLimit,
+	}
+
+	if q.Kind != "" {
+		if _, err := blueprint.Get(q.Kind); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+
+	if raw := values.Get("min_build_time"); raw != "" {
+		if q.MinBuildTime, err = time.ParseDuration(raw); err != nil {
+			return nil, fmt.Errorf("min_build_time: %w", err)
+		}
+	}
+
+	if raw := values.Get("max_build_time"); raw != "" {
+		if q.MaxBuildTime, err = time.ParseDuration(raw); err != nil {
+			return nil, fmt.Errorf("max_build_time: %w", err)
+		}
+	}
+
+	if raw := values.Get("offset"); raw != "" {
+		if q.Offset, err = strconv.Atoi(raw); err != nil || q.Offset < 0 {
+			return nil, fmt.Errorf("invalid offset: %s", raw)
+		}
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		if q.Limit, err = strconv.Atoi(raw); err != nil || q.Limit < 1 {
+			return nil, fmt.Errorf("invalid limit: %s", raw)
+		}
+	}
+
+	if q.Limit > searchMaxLimit {
+		q.Limit = searchMaxLimit
+	}
+
+	return q, nil
+}
+
+// buildingFilters reports whether the query uses fields only buildings have,
+// in which case no other kind matches.
+func (q *searchQuery) buildingFilters() bool {
+	return q.MinBuildTime > 0 || q.MaxBuildTime > 0 || q.CostResource != "" || q.Produces != ""
+}
+
+func (q *searchQuery) matchName(name, slug string) bool {
+	if q.Name == "" {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(name), q.Name) || strings.Contains(strings.ToLower(slug), q.Name)
+}
+
+// match reports whether bp, a blueprint of kind, matches the query.
+func (q *searchQuery) match(kind string, bp blueprint.Blueprint) bool {
+	if q.Kind != "" && q.Kind != kind {
+		return false
+	}
+
+	if building, ok := bp.(*proto.BuildingBlueprint); ok {
+		return q.matchBuilding(building)
+	}
+
+	if q.buildingFilters() {
+		return false
+	}
+
+	var name string
+	if named, ok := bp.(interface{ GetName() string }); ok {
+		name = named.GetName()
+	}
+
+	return q.matchName(name, bp.GetSlug())
+}
+
+func (q *searchQuery) matchBuilding(bp *proto.BuildingBlueprint) bool {
+	if !q.matchName(bp.GetName(), bp.GetSlug()) {
+		return false
+	}
+
+	buildTime := bp.GetBuildTime().AsDuration()
+
+	if q.MinBuildTime > 0 && buildTime < q.MinBuildTime {
+		return false
+	}
+
+	if q.MaxBuildTime > 0 && buildTime > q.MaxBuildTime {
+		return false
+	}
+
+	if q.CostResource != "" && !hasResource(bp.GetCost(), q.CostResource) {
+		return false
+	}
+
+	if q.Produces != "" {
+		produces := false
+
+		for _, production := range bp.GetProduction() {
+			if hasResource(production.GetOutput(), q.Produces) {
+				produces = true
+				break
+			}
+		}
+
+		if !produces {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasResource(list *proto.ResourceList, resource string) bool {
+	for _, item := range list.GetResources() {
+		if item.GetName() == resource {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SearchBlueprints filters a version by kind, name and building fields. The
+// active version is served from the cache, other versions from the registry.
+func SearchBlueprints() http.HandlerFunc {
+	logger := slog.Default().With("context", "SearchBlueprints")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		version, ok := routeVersion(w, r)
+		if !ok {
+			return
+		}
+
+		query, err := parseSearchQuery(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		set, err := loadVersion(r.Context(), version)
+		if err != nil {
+			logger.Error("failed to load version", "error", err, "version", version)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if set.Empty() {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		matches := make([]searchResult, 0)
+
+		for _, kind := range blueprint.Held() {
+			for _, bp := range set.Set[kind.Name] {
+				if query.match(kind.Name, bp) {
+					matches = append(matches, searchResult{Kind: kind.Name, Blueprint: bp})
+				}
+			}
+		}
+
+		response := searchResponse{
+			Version: version,
+			Total:   len(matches),
+			Offset:  query.Offset,
+			Limit:   query.Limit,
+			Items:   page(matches, query.Offset, query.Limit),
+		}
+
+		respond(w, r, response)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return make([]T, 0)
+	}
+
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[offset:end]
+}
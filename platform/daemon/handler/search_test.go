@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const searchVersion = "8.0.0"
+
+func TestSearchBlueprints(t *testing.T) {
+	setupStores(t)
+
+	buildings := []registry.BuildingBlueprintRequest{
+		{Name: "House", Slug: "house", BuildTime: "10s", Cost: registry.ResourceList{{Resource: "wood", Amount: 5}}},
+		{Name: "Farm", Slug: "farm", BuildTime: "30s", Cost: registry.ResourceList{{Resource: "wood", Amount: 10}}},
+		{Name: "Quarry", Slug: "quarry", BuildTime: "1m", Cost: registry.ResourceList{{Resource: "stone", Amount: 2}}},
+	}
+
+	resources := []registry.ResourceBlueprintRequest{
+		{Name: "Wood", Slug: "wood"},
+		{Name: "Stone", Slug: "stone"},
+	}
+
+	for _, building := range buildings {
+		require.NoError(t, registry.SaveBuildingBlueprint(context.Background(), searchVersion, building, false))
+	}
+
+	for _, resource := range resources {
+		require.NoError(t, registry.SaveResourceBlueprint(context.Background(), searchVersion, resource, false))
+	}
+
+	router := chi.NewRouter()
+	router.Get("/registry/blueprint/{version}/search", SearchBlueprints())
+
+	tests := []struct {
+		label          string
+		query          string
+		expectedStatus int
+		expectedTotal  int
+		expectedSlugs  []string
+	}{
+		{label: "all", query: "", expectedStatus: http.StatusOK, expectedTotal: 5, expectedSlugs: []string{"farm", "house", "quarry", "stone", "wood"}},
+		{label: "kind", query: "kind=resource", expectedStatus: http.StatusOK, expectedTotal: 2, expectedSlugs: []string{"stone", "wood"}},
+		{label: "name", query: "name=OU", expectedStatus: http.StatusOK, expectedTotal: 1, expectedSlugs: []string{"house"}},
+		{label: "build time", query: "min_build_time=20s&max_build_time=1m", expectedStatus: http.StatusOK, expectedTotal: 2, expectedSlugs: []string{"farm", "quarry"}},
+		{label: "cost", query: "cost=wood", expectedStatus: http.StatusOK, expectedTotal: 2, expectedSlugs: []string{"farm", "house"}},
+		{label: "page", query: "limit=2&offset=1", expectedStatus: http.StatusOK, expectedTotal: 5, expectedSlugs: []string{"house", "quarry"}},
+		{label: "past end", query: "offset=10", expectedStatus: http.StatusOK, expectedTotal: 5, expectedSlugs: []string{}},
+		{label: "invalid kind", query: "kind=unit", expectedStatus: http.StatusBadRequest},
+		{label: "invalid duration", query: "min_build_time=soon", expectedStatus: http.StatusBadRequest},
+		{label: "invalid limit", query: "limit=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/registry/blueprint/"+searchVersion+"/search?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Total int `json:"total"`
+				Items []struct {
+					Kind      string `json:"kind"`
+					Blueprint struct {
+						Slug string `json:"Slug"`
+					} `json:"blueprint"`
+				} `json:"items"`
+			}
+
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedTotal, response.Total)
+
+			slugs := make([]string, 0, len(response.Items))
+			for _, item := range response.Items {
+				slugs = append(slugs, item.Blueprint.Slug)
+			}
+
+			assert.Equal(t, tt.expectedSlugs, slugs)
+		}
+
+		t.Run(tt.label, tf)
+	}
+
+	t.Run("missing version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/registry/blueprint/9.9.9/search", nil)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
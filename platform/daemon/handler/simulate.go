@@ -0,0 +1,441 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/bits"
+	"net/http"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+)
+
+var (
+	errUnknownTarget = errors.New("unknown target")
+	errUnreachable   = errors.New("target can't be reached")
+	errOverflow      = errors.New("amounts out of range")
+)
+
+type simulationProduction struct {
+	Building string `json:"building"`
+	Resource string `json:"resource"`
+	Cycles   uint64 `json:"cycles"`
+	Time     string `json:"time"`
+
+	duration time.Duration
+}
+
+type simulationResponse struct {
+	Version        string                 `json:"version"`
+	Target         model.SimulationTarget `json:"target"`
+	BuildTime      string                 `json:"build_time"`
+	ProductionTime string                 `json:"production_time"`
+	TotalTime      string                 `json:"total_time"`
+	Buildings      map[string]uint64      `json:"buildings"`
+	Productions    []simulationProduction `json:"productions"`
+	Consumed       map[string]uint64      `json:"consumed"`
+	Gathered       map[string]uint64      `json:"gathered"`
+	Surplus        map[string]uint64      `json:"surplus"`
+}
+
+type producer struct {
+	building   *proto.BuildingBlueprint
+	production *proto.Production
+	output     uint64
+}
+
+// simulation plans the cheapest way to a target with one builder: buildings
+// are built one after the other and production cycles run one at a time.
+// Resources no building produces are gathered, they only add to the totals.
+type simulation struct {
+	buildings map[string]*proto.BuildingBlueprint
+	resources map[string]bool
+	producers map[string][]producer
+
+	stock    map[string]uint64
+	built    map[string]uint64
+	consumed map[string]uint64
+	gathered map[string]uint64
+	cycles   map[string]*simulationProduction
+	order    []string
+
+	// planning holds the buildings and resources being planned, needing one
+	// of them again means it can only be had by already having it.
+	planning map[string]bool
+
+	buildTime      time.Duration
+	productionTime time.Duration
+}
+
+func newSimulation(set *versionSet, available map[string]uint64) *simulation {
+	s := &simulation{
+		buildings: make(map[string]*proto.BuildingBlueprint, len(set.Set[model.KindBuilding])),
+		resources: make(map[string]bool, len(set.Set[model.KindResource])),
+		producers: make(map[string][]producer),
+		stock:     make(map[string]uint64, len(available)),
+		built:     make(map[string]uint64),
+		consumed:  make(map[string]uint64),
+		gathered:  make(map[string]uint64),
+		cycles:    make(map[string]*simulationProduction),
+		planning:  make(map[string]bool),
+	}
+
+	for resource, amount := range available {
+		s.stock[resource] = amount
+	}
+
+	for _, resource := range set.Resources() {
+		s.resources[resource.GetSlug()] = true
+	}
+
+	// Buildings are sorted by slug, so ties between producers always go to
+	// the same one.
+	for _, building := range set.Buildings() {
+		s.buildings[building.GetSlug()] = building
+
+		for _, production := range building.GetProduction() {
+			for _, item := range production.GetOutput().GetResources() {
+				if item.GetAmount() == 0 {
+					continue
+				}
+
+				s.producers[item.GetName()] = append(s.producers[item.GetName()], producer{
+					building:   building,
+					production: production,
+					output:     item.GetAmount(),
+				})
+			}
+		}
+	}
+
+	return s
+}
+
+func (s *simulation) run(target model.SimulationTarget) error {
+	switch target.Kind {
+	case model.KindBuilding:
+		if _, ok := s.buildings[target.Slug]; !ok {
+			return fmt.Errorf("%w: building %s", errUnknownTarget, target.Slug)
+		}
+
+		return s.build(target.Slug, target.Amount)
+	default:
+		if !s.resources[target.Slug] {
+			return fmt.Errorf("%w: resource %s", errUnknownTarget, target.Slug)
+		}
+
+		return s.obtain(target.Slug, target.Amount)
+	}
+}
+
+// build builds count copies of a building, paying for all of them at once.
+func (s *simulation) build(slug string, count uint64) error {
+	key := nodeID(model.KindBuilding, slug)
+	if s.planning[key] {
+		return fmt.Errorf("%w: building %s needs itself", errUnreachable, slug)
+	}
+
+	s.planning[key] = true
+	defer delete(s.planning, key)
+
+	building := s.buildings[slug]
+
+	for _, item := range building.GetCost().GetResources() {
+		amount, err := mulAmount(item.GetAmount(), count)
+		if err != nil {
+			return err
+		}
+
+		if err := s.consume(item.GetName(), amount); err != nil {
+			return err
+		}
+	}
+
+	built, err := addAmount(s.built[slug], count)
+	if err != nil {
+		return err
+	}
+
+	duration, err := mulDuration(building.GetBuildTime().AsDuration(), count)
+	if err != nil {
+		return err
+	}
+
+	if s.buildTime, err = addDuration(s.buildTime, duration); err != nil {
+		return err
+	}
+
+	s.built[slug] = built
+
+	return nil
+}
+
+func (s *simulation) consume(resource string, amount uint64) error {
+	consumed, err := addAmount(s.consumed[resource], amount)
+	if err != nil {
+		return err
+	}
+
+	s.consumed[resource] = consumed
+
+	return s.obtain(resource, amount)
+}
+
+// obtain takes amount of resource from the stock, producing what is missing.
+func (s *simulation) obtain(resource string, amount uint64) error {
+	taken := min(s.stock[resource], amount)
+	s.stock[resource] -= taken
+	amount -= taken
+
+	if amount == 0 {
+		return nil
+	}
+
+	producers := s.producers[resource]
+	if len(producers) == 0 {
+		gathered, err := addAmount(s.gathered[resource], amount)
+		if err != nil {
+			return err
+		}
+
+		s.gathered[resource] = gathered
+
+		return nil
+	}
+
+	key := nodeID(model.KindResource, resource)
+	if s.planning[key] {
+		return fmt.Errorf("%w: resource %s is needed to produce itself", errUnreachable, resource)
+	}
+
+	s.planning[key] = true
+	defer delete(s.planning, key)
+
+	p, cycles, ok := s.cheapest(producers, amount)
+	if !ok {
+		return fmt.Errorf("%w: no building producing %s can be built", errUnreachable, resource)
+	}
+
+	slug := p.building.GetSlug()
+
+	if s.built[slug] == 0 {
+		if err := s.build(slug, 1); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range p.production.GetCost().GetResources() {
+		cost, err := mulAmount(item.GetAmount(), cycles)
+		if err != nil {
+			return err
+		}
+
+		if err := s.consume(item.GetName(), cost); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range p.production.GetOutput().GetResources() {
+		output, err := mulAmount(item.GetAmount(), cycles)
+		if err != nil {
+			return err
+		}
+
+		if s.stock[item.GetName()], err = addAmount(s.stock[item.GetName()], output); err != nil {
+			return err
+		}
+	}
+
+	s.stock[resource] -= amount
+
+	duration, err := mulDuration(p.production.GetProductionTime().AsDuration(), cycles)
+	if err != nil {
+		return err
+	}
+
+	if s.productionTime, err = addDuration(s.productionTime, duration); err != nil {
+		return err
+	}
+
+	production, ok := s.cycles[slug+"/"+resource]
+	if !ok {
+		production = &simulationProduction{Building: slug, Resource: resource}
+		s.cycles[slug+"/"+resource] = production
+		s.order = append(s.order, slug+"/"+resource)
+	}
+
+	production.Cycles += cycles
+	production.duration += duration
+
+	return nil
+}
+
+// cheapest picks the producer that delivers amount soonest, counting the
+// build time of producers that aren't built yet. Producers of buildings being
+// planned are skipped.
+func (s *simulation) cheapest(producers []producer, amount uint64) (producer, uint64, bool) {
+	var (
+		best       producer
+		bestCycles uint64
+		bestTime   time.Duration
+		found      bool
+	)
+
+	for _, p := range producers {
+		if s.planning[nodeID(model.KindBuilding, p.building.GetSlug())] {
+			continue
+		}
+
+		cycles := amount/p.output + min(amount%p.output, 1)
+
+		// Producers whose time doesn't fit a duration can't be the cheapest.
+		cost, err := mulDuration(p.production.GetProductionTime().AsDuration(), cycles)
+		if err != nil {
+			cost = math.MaxInt64
+		}
+
+		if s.built[p.building.GetSlug()] == 0 {
+			if cost, err = addDuration(cost, p.building.GetBuildTime().AsDuration()); err != nil {
+				cost = math.MaxInt64
+			}
+		}
+
+		if !found || cost < bestTime {
+			best, bestCycles, bestTime, found = p, cycles, cost, true
+		}
+	}
+
+	return best, bestCycles, found
+}
+
+func mulAmount(a, b uint64) (uint64, error) {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return 0, fmt.Errorf("%w: %d * %d", errOverflow, a, b)
+	}
+
+	return lo, nil
+}
+
+func addAmount(a, b uint64) (uint64, error) {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return 0, fmt.Errorf("%w: %d + %d", errOverflow, a, b)
+	}
+
+	return sum, nil
+}
+
+// mulDuration and addDuration expect non-negative durations, negative ones are
+// counted as zero.
+func mulDuration(d time.Duration, n uint64) (time.Duration, error) {
+	if d <= 0 || n == 0 {
+		return 0, nil
+	}
+
+	if n > uint64(math.MaxInt64/d) {
+		return 0, fmt.Errorf("%w: %s * %d", errOverflow, d, n)
+	}
+
+	return d * time.Duration(n), nil
+}
+
+func addDuration(a, b time.Duration) (time.Duration, error) {
+	a, b = max(a, 0), max(b, 0)
+
+	if a > math.MaxInt64-b {
+		return 0, fmt.Errorf("%w: %s + %s", errOverflow, a, b)
+	}
+
+	return a + b, nil
+}
+
+func (s *simulation) response(version string, target model.SimulationTarget) *simulationResponse {
+	response := &simulationResponse{
+		Version:        version,
+		Target:         target,
+		BuildTime:      s.buildTime.String(),
+		ProductionTime: s.productionTime.String(),
+		TotalTime:      (s.buildTime + s.productionTime).String(),
+		Buildings:      s.built,
+		Productions:    make([]simulationProduction, 0, len(s.order)),
+		Consumed:       s.consumed,
+		Gathered:       s.gathered,
+		Surplus:        make(map[string]uint64),
+	}
+
+	// Productions are listed in the order they were planned.
+	for _, key := range s.order {
+		production := s.cycles[key]
+		production.Time = production.duration.String()
+
+		response.Productions = append(response.Productions, *production)
+	}
+
+	for resource, amount := range s.stock {
+		if amount > 0 {
+			response.Surplus[resource] = amount
+		}
+	}
+
+	return response
+}
+
+// SimulateEconomy computes the time and resources a target takes with the
+// buildings of a version, so balance changes can be compared across versions.
+func SimulateEconomy() http.HandlerFunc {
+	logger := slog.Default().With("context", "SimulateEconomy")
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		req, err := decodeRequest[*model.SimulationRequest](r)
+		if err != nil {
+			logger.Debug("failed to decode simulation request", "error", err)
+			writeDecodeError(w, err)
+
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		version, err := resolveVersion(r.Context(), req.Version, "", false)
+		if err != nil {
+			writeResolveError(w, err)
+			return
+		}
+
+		set, err := loadVersion(r.Context(), version)
+		if err != nil {
+			logger.Error("failed to load version", "error", err, "version", version)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		if set.Empty() {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		sim := newSimulation(set, req.Available)
+
+		if err := sim.run(req.Target); err != nil {
+			switch {
+			case errors.Is(err, errUnknownTarget), errors.Is(err, errOverflow):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			}
+
+			return
+		}
+
+		respond(w, r, sim.response(version, req.Target))
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const simulateVersion = "16.0.0"
+
+func TestSimulateEconomy(t *testing.T) {
+	setupStores(t)
+
+	buildings := []registry.BuildingBlueprintRequest{
+		{
+			Name: "Sawmill", Slug: "sawmill", BuildTime: "10s",
+			Cost: registry.ResourceList{{Resource: "wood", Amount: 10}},
+			Production: []registry.Production{{
+				Cost:           registry.ResourceList{{Resource: "wood", Amount: 2}},
+				Product:        registry.ResourceList{{Resource: "plank", Amount: 1}},
+				ProductionTime: "5s",
+			}},
+		},
+		{Name: "House", Slug: "house", BuildTime: "20s", Cost: registry.ResourceList{{Resource: "plank", Amount: 4}, {Resource: "wood", Amount: 2}}},
+		{
+			Name: "Mine", Slug: "mine", BuildTime: "10s",
+			Cost: registry.ResourceList{{Resource: "iron", Amount: 1}},
+			Production: []registry.Production{{
+				Product:        registry.ResourceList{{Resource: "iron", Amount: 1}},
+				ProductionTime: "3s",
+			}},
+		},
+		{Name: "Forge", Slug: "forge", BuildTime: "10s", Cost: registry.ResourceList{{Resource: "iron", Amount: 3}}},
+		{Name: "Palace", Slug: "palace", BuildTime: "1h", Cost: registry.ResourceList{{Resource: "wood", Amount: 1 << 63}}},
+	}
+
+	resources := []registry.ResourceBlueprintRequest{
+		{Name: "Wood", Slug: "wood"},
+		{Name: "Plank", Slug: "plank"},
+		{Name: "Iron", Slug: "iron"},
+	}
+
+	for _, building := range buildings {
+		require.NoError(t, registry.SaveBuildingBlueprint(context.Background(), simulateVersion, building, false))
+	}
+
+	for _, resource := range resources {
+		require.NoError(t, registry.SaveResourceBlueprint(context.Background(), simulateVersion, resource, false))
+	}
+
+	router := chi.NewRouter()
+	router.Post("/registry/simulate", SimulateEconomy())
+
+	tests := []struct {
+		label          string
+		body           string
+		expectedStatus int
+		expected       *simulationResponse
+	}{
+		{
+			label:          "buildings",
+			body:           `{"version": "` + simulateVersion + `", "target": {"kind": "building", "slug": "house", "amount": 3}}`,
+			expectedStatus: http.StatusOK,
+			expected: &simulationResponse{
+				BuildTime:      "1m10s",
+				ProductionTime: "1m0s",
+				TotalTime:      "2m10s",
+				Buildings:      map[string]uint64{"house": 3, "sawmill": 1},
+				Productions:    []simulationProduction{{Building: "sawmill", Resource: "plank", Cycles: 12, Time: "1m0s"}},
+				Consumed:       map[string]uint64{"plank": 12, "wood": 40},
+				Gathered:       map[string]uint64{"wood": 40},
+				Surplus:        map[string]uint64{},
+			},
+		},
+		{
+			label:          "available",
+			body:           `{"version": "` + simulateVersion + `", "target": {"kind": "building", "slug": "house"}, "available": {"plank": 6}}`,
+			expectedStatus: http.StatusOK,
+			expected: &simulationResponse{
+				BuildTime:      "20s",
+				ProductionTime: "0s",
+				TotalTime:      "20s",
+				Buildings:      map[string]uint64{"house": 1},
+				Productions:    []simulationProduction{},
+				Consumed:       map[string]uint64{"plank": 4, "wood": 2},
+				Gathered:       map[string]uint64{"wood": 2},
+				Surplus:        map[string]uint64{"plank": 2},
+			},
+		},
+		{
+			label:          "resource",
+			body:           `{"version": "` + simulateVersion + `", "target": {"kind": "resource", "slug": "plank", "amount": 3}}`,
+			expectedStatus: http.StatusOK,
+			expected: &simulationResponse{
+				BuildTime:      "10s",
+				ProductionTime: "15s",
+				TotalTime:      "25s",
+				Buildings:      map[string]uint64{"sawmill": 1},
+				Productions:    []simulationProduction{{Building: "sawmill", Resource: "plank", Cycles: 3, Time: "15s"}},
+				Consumed:       map[string]uint64{"wood": 16},
+				Gathered:       map[string]uint64{"wood": 16},
+				Surplus:        map[string]uint64{},
+			},
+		},
+		{label: "unreachable", body: `{"version": "` + simulateVersion + `", "target": {"kind": "building", "slug": "forge"}}`, expectedStatus: http.StatusUnprocessableEntity},
+		{label: "unknown target", body: `{"version": "` + simulateVersion + `", "target": {"kind": "building", "slug": "castle"}}`, expectedStatus: http.StatusBadRequest},
+		{label: "amount above cap", body: `{"version": "` + simulateVersion + `", "target": {"kind": "building", "slug": "house", "amount": 1000001}}`, expectedStatus: http.StatusBadRequest},
+		{label: "overflow", body: `{"version": "` + simulateVersion + `", "target": {"kind": "building", "slug": "palace", "amount": 2}}`, expectedStatus: http.StatusBadRequest},
+		{label: "invalid kind", body: `{"version": "` + simulateVersion + `", "target": {"kind": "unit", "slug": "house"}}`, expectedStatus: http.StatusBadRequest},
+		{label: "missing version", body: `{"target": {"kind": "building", "slug": "house"}}`, expectedStatus: http.StatusBadRequest},
+		{label: "unknown version", body: `{"version": "16.0.1", "target": {"kind": "building", "slug": "house"}}`, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/registry/simulate", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+
+			if tt.expected == nil {
+				return
+			}
+
+			var actual simulationResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actual))
+
+			assert.Equal(t, simulateVersion, actual.Version)
+
+			actual.Version, actual.Target = "", model.SimulationTarget{}
+			assert.Equal(t, *tt.expected, actual)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/lru"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/snapshot"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+)
+
+// versionSet is every blueprint of a single version, sorted by slug.
+type versionSet struct {
+	Version string
+	blueprint.Set
+}
+
+func (v *versionSet) Empty() bool {
+	return v.Len() == 0
+}
+
+const (
+	versionCurrent = "current"
+	versionLatest  = "latest"
+)
+
+var errNoVersion = errors.New("no matching version")
+
+// resolveVersion strips the optional v prefix and maps aliases to versions:
+// current is the version loaded in the cache, latest the highest promoted
+// version that isn't a prerelease and latest-1.4 the highest promoted 1.4
+// version. A range like ">=1.2 <2" narrows latest down further, and drafts
+// lets latest pick drafts as well. Names that aren't semantic versions only
+// resolve to versions that already exist.
+func resolveVersion(ctx context.Context, version, constraint string, drafts bool) (string, error) {
+	version = strings.TrimPrefix(version, "v")
+
+	switch {
+	case version == versionCurrent:
+		return cluster.Active(), nil
+	case version == versionLatest:
+		return latestVersion(ctx, drafts, constraint)
+	case strings.HasPrefix(version, versionLatest+"-"):
+		prefix := strings.TrimPrefix(version, versionLatest+"-")
+		if _, err := semver.NewVersion(prefix); err != nil {
+			return "", fmt.Errorf("%w %q: %s", metadata.ErrInvalidVersion, version, err)
+		}
+
+		return latestVersion(ctx, drafts, prefix, constraint)
+	case isActiveVersion(version):
+		// The active version may predate semantic versions.
+		return version, nil
+	}
+
+	if _, err := metadata.ParseVersion(version); err != nil {
+		known, lookupErr := knownVersion(ctx, version)
+		if lookupErr != nil {
+			return "", lookupErr
+		}
+
+		if !known {
+			return "", err
+		}
+	}
+
+	return version, nil
+}
+
+// knownVersion reports whether version has a lifecycle record, or blueprints
+// from before the records were kept.
+func knownVersion(ctx context.Context, version string) (bool, error) {
+	store, err := metadata.Get()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = store.GetVersion(ctx, version)
+	if err == nil {
+		return true, nil
+	}
+
+	if !errors.Is(err, metadata.ErrNotFound) {
+		return false, err
+	}
+
+	set, err := loadVersion(ctx, version)
+	if err != nil {
+		return false, err
+	}
+
+	return !set.Empty(), nil
+}
+
+// latestVersion returns the highest version satisfying every constraint, only
+// promoted ones unless drafts is set.
+func latestVersion(ctx context.Context, drafts bool, constraints ...string) (string, error) {
+	parsed := make([]*semver.Constraints, 0, len(constraints))
+
+	for _, constraint := range constraints {
+		if constraint == "" {
+			continue
+		}
+
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return "", fmt.Errorf("%w range %q: %s", metadata.ErrInvalidVersion, constraint, err)
+		}
+
+		parsed = append(parsed, c)
+	}
+
+	store, err := metadata.Get()
+	if err != nil {
+		return "", err
+	}
+
+	versions, err := store.ListVersions(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	latest, ok := metadata.LatestVersion(versions, drafts, parsed...)
+	if !ok {
+		return "", errNoVersion
+	}
+
+	return latest, nil
+}
+
+// routeVersion resolves the version of the route, writing the error response
+// when it returns false.
+func routeVersion(w http.ResponseWriter, r *http.Request) (string, bool) {
+	query := r.URL.Query()
+
+	version, err := resolveVersion(r.Context(), chi.URLParam(r, "version"), query.Get("range"), query.Get("drafts") == "true")
+	if err != nil {
+		writeResolveError(w, err)
+		return "", false
+	}
+
+	return version, true
+}
+
+func writeResolveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, metadata.ErrInvalidVersion):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, errNoVersion):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	default:
+		slog.Error("failed to resolve version", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func isActiveVersion(version string) bool {
+	return version != "" && version == cluster.Active()
+}
+
+// loadVersion reads a whole version, from the cache if it is the active one and
+// from the registry otherwise. Missing versions come back empty.
+func loadVersion(ctx context.Context, version string) (*versionSet, error) {
+	if isActiveVersion(version) {
+		return activeVersion(ctx, version), nil
+	}
+
+	set, ok, err := cachedVersion(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		return set, nil
+	}
+
+	return readVersion(ctx, version)
+}
+
+func activeVersion(ctx context.Context, version string) *versionSet {
+	if set, ok := staleVersion(version); ok {
+		return set
+	}
+
+	return &versionSet{Version: version, Set: blueprint.FromCache(ctx)}
+}
+
+// staleVersion returns the snapshot served in place of the cache while the
+// registry is unreachable.
+func staleVersion(version string) (*versionSet, bool) {
+	snap, ok := snapshot.Stale()
+	if !ok || snap.Version != version {
+		return nil, false
+	}
+
+	return snapshotSet(snap), true
+}
+
+func snapshotSet(snap *snapshot.Snapshot) *versionSet {
+	return &versionSet{Version: snap.Version, Set: snap.Blueprints}
+}
+
+// readVersion reads a whole version from the registry.
+func readVersion(ctx context.Context, version string) (*versionSet, error) {
+	set, err := blueprint.ReadSet(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &versionSet{Version: version, Set: set}, nil
+}
+
+// find looks up a blueprint of the set. held is false for kinds the set
+// doesn't hold.
+func (v *versionSet) find(kind, slug string) (bp any, found bool, held bool) {
+	registered, err := blueprint.Get(kind)
+	if err != nil || !registered.Held() {
+		return nil, false, false
+	}
+
+	bp, found = v.Find(kind, slug)
+
+	return bp, found, true
+}
+
+var (
+	versionCacheOnce = &sync.Once{}
+	versionCache     *lru.Cache[*versionSet]
+)
+
+// cachedVersion serves promoted versions from an LRU of fully loaded versions.
+// Promoted versions are frozen, so entries never go stale. ok is false for
+// versions that may still change. Promoted versions are also kept on disk and
+// served from there while the registry is unreachable.
+func cachedVersion(ctx context.Context, version string) (*versionSet, bool, error) {
+	versionCacheOnce.Do(func() {
+		versionCache = lru.New("blueprint.versions", viper.GetInt(config.FlagBlueprintCache), readFrozenVersion)
+	})
+
+	if set, ok := versionCache.Peek(ctx, version); ok {
+		return set, true, nil
+	}
+
+	frozen, err := isFrozen(ctx, version)
+	if err != nil {
+		return diskVersion(version, err)
+	}
+
+	if !frozen {
+		return nil, false, nil
+	}
+
+	set, err := versionCache.Get(ctx, version)
+	if err != nil {
+		return diskVersion(version, err)
+	}
+
+	return set, true, nil
+}
+
+// readFrozenVersion reads a promoted version from the registry and keeps a
+// snapshot of it.
+func readFrozenVersion(ctx context.Context, version string) (*versionSet, error) {
+	set, err := readVersion(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir := viper.GetString(config.FlagSnapshotDir); dir != "" {
+		snap := &snapshot.Snapshot{
+			Version:    version,
+			SavedAt:    time.Now().UTC(),
+			Blueprints: set.Set,
+		}
+
+		if err := snapshot.Write(dir, snap); err != nil {
+			slog.Warn("failed to save blueprint snapshot", "error", err, "version", version)
+		}
+	}
+
+	return set, nil
+}
+
+// diskVersion serves the snapshot of a promoted version after reading it from
+// the registry failed with cause.
+func diskVersion(version string, cause error) (*versionSet, bool, error) {
+	dir := viper.GetString(config.FlagSnapshotDir)
+	if dir == "" {
+		return nil, false, cause
+	}
+
+	snap, err := snapshot.Read(dir, version)
+	if err != nil {
+		return nil, false, cause
+	}
+
+	slog.Warn("registry is unreachable, serving stale blueprint snapshot", "error", cause, "version", version, "saved_at", snap.SavedAt)
+
+	return snapshotSet(snap), true, nil
+}
+
+func isFrozen(ctx context.Context, version string) (bool, error) {
+	store, err := metadata.Get()
+	if err != nil {
+		return false, err
+	}
+
+	v, err := store.GetVersion(ctx, version)
+	if err != nil {
+		if errors.Is(err, metadata.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return v.Frozen(), nil
+}
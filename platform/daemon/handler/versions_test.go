@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/registrar"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/snapshot"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionCache(t *testing.T) {
+	setupStores(t)
+
+	ctx := context.Background()
+
+	store, err := metadata.Get()
+	require.NoError(t, err)
+
+	const (
+		promoted = "7.0.0"
+		draft    = "7.1.0"
+	)
+
+	for _, version := range []string{promoted, draft} {
+		require.NoError(t, registry.SaveBuildingBlueprint(ctx, version, registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"}, false))
+	}
+
+	v := metadata.NewVersion(promoted)
+	require.NoError(t, v.Promote(metadata.StateStaging, "test"))
+	require.NoError(t, store.SaveVersion(ctx, v))
+
+	require.NoError(t, store.SaveVersion(ctx, metadata.NewVersion(draft)))
+
+	router := chi.NewRouter()
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}", GetBlueprint())
+
+	get := func(version, slug string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/registry/blueprint/"+version+"/building/"+slug, nil))
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get(promoted, "house"))
+	assert.Equal(t, http.StatusOK, get(draft, "house"))
+	assert.Equal(t, http.StatusNotFound, get(promoted, "hut"))
+
+	// Remove the rows behind the cache's back, only the draft notices.
+	buildings, err := blueprint.Get(blueprint.KindBuilding)
+	require.NoError(t, err)
+
+	require.NoError(t, buildings.Delete(ctx, promoted, "house"))
+	require.NoError(t, buildings.Delete(ctx, draft, "house"))
+
+	assert.Equal(t, http.StatusOK, get(promoted, "house"))
+	assert.Equal(t, http.StatusNotFound, get(draft, "house"))
+}
+
+func TestStaleSnapshot(t *testing.T) {
+	const version = "10.0.0"
+
+	cluster.SetActive(version)
+	t.Cleanup(func() {
+		cluster.SetActive("")
+		snapshot.Recover()
+	})
+
+	snapshot.Serve(&snapshot.Snapshot{
+		Version: version,
+		Blueprints: blueprint.Set{
+			blueprint.KindBuilding: {&proto.BuildingBlueprint{Name: "House", Slug: "house"}},
+			blueprint.KindResource: {&proto.ResourceBlueprint{Name: "Wood", Slug: "wood"}},
+		},
+	}, errors.New("connection refused"))
+
+	router := chi.NewRouter()
+	router.Get("/health", Health())
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}", GetBlueprint())
+	router.Get("/registry/blueprint/{version}", GetBlueprints())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, get("/registry/blueprint/current/building/house").Code)
+	assert.Equal(t, http.StatusOK, get("/registry/blueprint/current/resource/wood").Code)
+	assert.Equal(t, http.StatusNotFound, get("/registry/blueprint/current/building/hut").Code)
+
+	rec := get("/registry/blueprint/current")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"house"`)
+
+	health := func() healthResponse {
+		rec := get("/health")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response healthResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+		return response
+	}
+
+	response := health()
+	assert.Equal(t, healthDegraded, response.Status)
+	assert.True(t, response.Blueprints.Stale)
+	assert.Equal(t, "connection refused", response.Blueprints.LastError)
+
+	snapshot.Recover()
+
+	response = health()
+	assert.Equal(t, healthOK, response.Status)
+	assert.False(t, response.Blueprints.Stale)
+}
+
+func TestVersionAliases(t *testing.T) {
+	setupStores(t)
+
+	ctx := context.Background()
+
+	store, err := metadata.Get()
+	require.NoError(t, err)
+
+	for _, version := range []string{"20.0.0", "20.1.0", "20.2.0-rc.1", "21.0.0", "21.1.0"} {
+		require.NoError(t, registrar.CheckWritable(ctx, version))
+		require.NoError(t, registry.SaveBuildingBlueprint(ctx, version, registry.BuildingBlueprintRequest{Name: "House " + version, Slug: "house", BuildTime: "10s"}, false))
+
+		// 21.1.0 stays a draft.
+		if version != "21.1.0" {
+			v, err := store.GetVersion(ctx, version)
+			require.NoError(t, err)
+			require.NoError(t, v.Promote(metadata.StateStaging, "test"))
+			require.NoError(t, store.SaveVersion(ctx, v))
+		}
+	}
+
+	router := chi.NewRouter()
+	router.Get("/registry/blueprint/{version}/{kind}/{slug}", GetBlueprint())
+	router.Get("/registry/versions", ListVersions())
+
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != http.StatusOK {
+			return rec.Code, ""
+		}
+
+		var bp map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &bp))
+
+		return rec.Code, lowerKeys(bp)["name"].(string)
+	}
+
+	tests := []struct {
+		label        string
+		path         string
+		expectedCode int
+		expectedName string
+	}{
+		{label: "exact", path: "/registry/blueprint/20.1.0/building/house", expectedCode: http.StatusOK, expectedName: "House 20.1.0"},
+		{label: "prefixed", path: "/registry/blueprint/v20.1.0/building/house", expectedCode: http.StatusOK, expectedName: "House 20.1.0"},
+		{label: "latest-major", path: "/registry/blueprint/latest-20/building/house", expectedCode: http.StatusOK, expectedName: "House 20.1.0"},
+		{label: "latest-minor", path: "/registry/blueprint/latest-20.0/building/house", expectedCode: http.StatusOK, expectedName: "House 20.0.0"},
+		{label: "latest-range", path: "/registry/blueprint/latest/building/house?range=%3E%3D20+%3C22", expectedCode: http.StatusOK, expectedName: "House 21.0.0"},
+		{label: "latest-drafts", path: "/registry/blueprint/latest-21/building/house?drafts=true", expectedCode: http.StatusOK, expectedName: "House 21.1.0"},
+		{label: "draft", path: "/registry/blueprint/21.1.0/building/house", expectedCode: http.StatusOK, expectedName: "House 21.1.0"},
+		{label: "prerelease", path: "/registry/blueprint/20.2.0-rc.1/building/house", expectedCode: http.StatusOK, expectedName: "House 20.2.0-rc.1"},
+		{label: "latest-missing", path: "/registry/blueprint/latest-90/building/house", expectedCode: http.StatusNotFound},
+		{label: "invalid", path: "/registry/blueprint/20.1/building/house", expectedCode: http.StatusBadRequest},
+		{label: "unknown name", path: "/registry/blueprint/nightly/building/house", expectedCode: http.StatusBadRequest},
+		{label: "invalid-range", path: "/registry/blueprint/latest/building/house?range=bogus", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			code, name := get(tt.path)
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expectedName, name)
+		}
+
+		t.Run(tt.label, tf)
+	}
+
+	assert.ErrorIs(t, registrar.CheckWritable(ctx, "20.3"), metadata.ErrInvalidVersion)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/registry/versions?range=20", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var listed struct {
+		Versions []metadata.Version `json:"versions"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+
+	names := make([]string, 0, len(listed.Versions))
+	for _, v := range listed.Versions {
+		names = append(names, v.Version)
+	}
+
+	assert.Equal(t, []string{"20.0.0", "20.1.0"}, names)
+}
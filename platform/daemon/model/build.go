@@ -0,0 +1,25 @@
+package model
+
+import (
+	"errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BuildRequest asks for a building of the active version to be constructed.
+type BuildRequest struct {
+	Building string `json:"building"`
+}
+
+func (b *BuildRequest) UnmarshalYAML(x *yaml.Node) error {
+	return decodeYAMLAsJSON(x, b)
+}
+
+// Validate checks that the request names a building.
+func (b *BuildRequest) Validate() error {
+	if b.Building == "" {
+		return errors.New("missing building")
+	}
+
+	return nil
+}
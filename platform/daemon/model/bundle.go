@@ -0,0 +1,249 @@
+package model
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"gopkg.in/yaml.v3"
+)
+
+// Requests splits a batch into one BlueprintRequest per blueprint, ordered by
+// kind name. Blueprints created from a template carry its overrides, templates
+// that were not resolved yet come last and have no definition.
+func (b *BlueprintBatchRequest) Requests() []BlueprintRequest {
+	names := make([]string, 0, len(b.Blueprints))
+	for name := range b.Blueprints {
+		names = append(names, name)
+	}
+
+	for name := range b.Templates {
+		if _, ok := b.Blueprints[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	requests := make([]BlueprintRequest, 0, b.Len())
+
+	for _, name := range names {
+		templates := make(map[string]Template)
+		for _, template := range b.Templates[name] {
+			templates[template.Slug] = template
+		}
+
+		for _, def := range b.Blueprints[name] {
+			req := BlueprintRequest{
+				Kind:       name,
+				Version:    b.Version,
+				Force:      b.Force,
+				Definition: def,
+			}
+
+			req.Locales = b.Locales[name][def.GetSlug()]
+
+			if template, ok := templates[def.GetSlug()]; ok {
+				req.Extends = template.Extends
+				req.Overrides = template.Overrides
+
+				delete(templates, def.GetSlug())
+			}
+
+			requests = append(requests, req)
+		}
+
+		for _, template := range b.Templates[name] {
+			if _, ok := templates[template.Slug]; !ok {
+				continue
+			}
+
+			requests = append(requests, BlueprintRequest{
+				Kind:      name,
+				Version:   b.Version,
+				Force:     b.Force,
+				Extends:   template.Extends,
+				Overrides: template.Overrides,
+				Locales:   b.Locales[name][template.Slug],
+			})
+		}
+	}
+
+	return requests
+}
+
+// Add appends a single blueprint request to the batch.
+func (b *BlueprintBatchRequest) Add(req BlueprintRequest) error {
+	if _, err := blueprint.Get(req.Kind); err != nil {
+		return err
+	}
+
+	if req.Definition == nil && req.Extends == "" {
+		return fmt.Errorf("%s: missing definition", req.Kind)
+	}
+
+	if req.Extends != "" {
+		template, err := NewTemplate(req.Extends, req.Overrides)
+		if err != nil {
+			return fmt.Errorf("%s: %w", req.Kind, err)
+		}
+
+		if b.Templates == nil {
+			b.Templates = make(map[string][]Template)
+		}
+
+		b.Templates[req.Kind] = append(b.Templates[req.Kind], template)
+	}
+
+	b.SetLocales(req.Kind, req.Slug(), req.Locales)
+
+	if req.Definition == nil {
+		return nil
+	}
+
+	if b.Blueprints == nil {
+		b.Blueprints = make(map[string][]registry.Request)
+	}
+
+	b.Blueprints[req.Kind] = append(b.Blueprints[req.Kind], req.Definition)
+
+	return nil
+}
+
+// Len is the number of blueprints in the batch, counting templates that were
+// not resolved yet.
+func (b *BlueprintBatchRequest) Len() int {
+	total := 0
+	for name, defs := range b.Blueprints {
+		total += len(defs)
+
+		slugs := make(map[string]bool, len(defs))
+		for _, def := range defs {
+			slugs[def.GetSlug()] = true
+		}
+
+		for _, template := range b.Templates[name] {
+			if !slugs[template.Slug] {
+				total++
+			}
+		}
+	}
+
+	for name, templates := range b.Templates {
+		if _, ok := b.Blueprints[name]; !ok {
+			total += len(templates)
+		}
+	}
+
+	return total
+}
+
+// Slug is the slug of the blueprint, read from the overrides of a request that
+// was not resolved yet.
+func (b BlueprintRequest) Slug() string {
+	if b.Definition != nil {
+		return b.Definition.GetSlug()
+	}
+
+	if b.Extends != "" {
+		if template, err := NewTemplate(b.Extends, b.Overrides); err == nil {
+			return template.Slug
+		}
+	}
+
+	return ""
+}
+
+// FileName is the path of a blueprint inside an exported bundle.
+func (b BlueprintRequest) FileName() string {
+	name := b.Slug()
+	if name == "" && b.Definition != nil {
+		name = b.Definition.GetName()
+	}
+
+	return path.Join(b.Kind, name+".yaml")
+}
+
+// WriteTarGz writes the batch as a gzipped tarball with one YAML file per
+// blueprint.
+func WriteTarGz(w io.Writer, batch *BlueprintBatchRequest) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	now := time.Now()
+
+	for _, req := range batch.Requests() {
+		raw, err := yaml.Marshal(req)
+		if err != nil {
+			return err
+		}
+
+		header := &tar.Header{
+			Name:    req.FileName(),
+			Mode:    0o644,
+			Size:    int64(len(raw)),
+			ModTime: now,
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if _, err := tw.Write(raw); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// ReadTarGz reads a bundle written by WriteTarGz back into a batch.
+func ReadTarGz(r io.Reader) (*BlueprintBatchRequest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	batch := &BlueprintBatchRequest{}
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		var req BlueprintRequest
+		if err := yaml.NewDecoder(tr).Decode(&req); err != nil {
+			return nil, fmt.Errorf("%s: %w", header.Name, err)
+		}
+
+		batch.Version = req.Version
+
+		if err := batch.Add(req); err != nil {
+			return nil, fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+
+	return batch, nil
+}
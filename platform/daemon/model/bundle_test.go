@@ -0,0 +1,115 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var testBatch = &BlueprintBatchRequest{
+	Version: "1.0.0",
+	Blueprints: map[string][]registry.Request{
+		KindBuilding: {
+			registry.BuildingBlueprintRequest{
+				Name:      "House",
+				Slug:      "house",
+				BuildTime: "10s",
+				Cost: registry.ResourceList{
+					{Resource: "wood", Amount: 10},
+				},
+				Production: []registry.Production{
+					{
+						Cost:           registry.ResourceList{{Resource: "wood", Amount: 1}},
+						Product:        registry.ResourceList{{Resource: "plank", Amount: 2}},
+						ProductionTime: "1m0s",
+					},
+				},
+			},
+		},
+		KindResource: {
+			registry.ResourceBlueprintRequest{Name: "Wood", Slug: "wood"},
+			registry.ResourceBlueprintRequest{Name: "Plank", Slug: "plank"},
+		},
+	},
+}
+
+func TestBatchRoundTrip(t *testing.T) {
+	tests := []struct {
+		label  string
+		encode func(*BlueprintBatchRequest) ([]byte, error)
+		decode func([]byte) (*BlueprintBatchRequest, error)
+	}{
+		{
+			label:  "json",
+			encode: func(b *BlueprintBatchRequest) ([]byte, error) { return json.Marshal(b) },
+			decode: func(d []byte) (*BlueprintBatchRequest, error) {
+				var b BlueprintBatchRequest
+				return &b, json.Unmarshal(d, &b)
+			},
+		},
+		{
+			label:  "yaml",
+			encode: func(b *BlueprintBatchRequest) ([]byte, error) { return yaml.Marshal(b) },
+			decode: func(d []byte) (*BlueprintBatchRequest, error) {
+				var b BlueprintBatchRequest
+				return &b, yaml.Unmarshal(d, &b)
+			},
+		},
+		{
+			label: "tar.gz",
+			encode: func(b *BlueprintBatchRequest) ([]byte, error) {
+				buf := bytes.NewBuffer(nil)
+				err := WriteTarGz(buf, b)
+
+				return buf.Bytes(), err
+			},
+			decode: func(d []byte) (*BlueprintBatchRequest, error) { return ReadTarGz(bytes.NewReader(d)) },
+		},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			raw, err := tt.encode(testBatch)
+			require.NoError(t, err)
+
+			decoded, err := tt.decode(raw)
+			require.NoError(t, err)
+
+			assert.Equal(t, testBatch, decoded)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
+
+func TestBatchYAMLFieldNames(t *testing.T) {
+	raw, err := yaml.Marshal(testBatch)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(raw), "build_time: 10s")
+	assert.Contains(t, string(raw), "production_time: 1m0s")
+}
+
+func TestBatchCollectionKeys(t *testing.T) {
+	raw := []byte(`{"version":"1.0.0","buildings":[{"name":"House","slug":"house","build_time":"10s"}],"resource":[{"name":"Wood","slug":"wood"}]}`)
+
+	var batch BlueprintBatchRequest
+	require.NoError(t, json.Unmarshal(raw, &batch))
+
+	assert.Equal(t, 2, batch.Len())
+	assert.Len(t, batch.Blueprints[KindBuilding], 1)
+	assert.Len(t, batch.Blueprints[KindResource], 1)
+
+	encoded, err := json.Marshal(batch)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"resources":[`)
+
+	err = json.Unmarshal([]byte(`{"version":"1.0.0","units":[]}`), &batch)
+	assert.ErrorIs(t, err, blueprint.ErrUnknownKind)
+}
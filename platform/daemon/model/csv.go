@@ -0,0 +1,751 @@
+package model
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Blueprints of a kind are read from and written to CSV one row per blueprint.
+// Columns are named after the JSON fields of the kind:
+//
+//   - scalar fields use their name: name, slug, build_time
+//   - lists of amounts, items of a string and a number field like costs, get a
+//     column per key holding the amount: cost.wood, cost.stone
+//   - other lists are indexed from 0: production.0.production_time,
+//     production.0.cost.wood
+//   - nested objects join their field names with dots
+//
+// Empty cells leave the field out. Indexed items without any filled cell are
+// dropped, the remaining ones keep their order.
+
+// CellError is an error of a single row of a CSV file, and of a column if it
+// can be pinned to one. Rows are line numbers, the header being row 1.
+type CellError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e CellError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+	}
+
+	return fmt.Sprintf("row %d, column %s: %s", e.Row, e.Column, e.Err)
+}
+
+func (e CellError) Unwrap() error { return e.Err }
+
+// CSVError collects the errors of every row of a CSV file.
+type CSVError struct {
+	Kind  string
+	Cells []CellError
+}
+
+func (e *CSVError) Error() string {
+	lines := make([]string, 0, len(e.Cells))
+	for _, cell := range e.Cells {
+		lines = append(lines, cell.Error())
+	}
+
+	return fmt.Sprintf("%s: %s", e.Kind, strings.Join(lines, "; "))
+}
+
+var errUnknownColumn = errors.New("unknown column")
+
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepIndex
+	stepKey
+)
+
+// step is a segment of a column name, resolved against the type of the kind.
+type step struct {
+	kind  stepKind
+	name  string
+	index int
+	order int
+
+	// Lists of amounts name the fields holding the key and the amount.
+	keyField   string
+	valueField string
+}
+
+type column struct {
+	name  string
+	steps []step
+	leaf  reflect.Type
+}
+
+// ReadCSV decodes the blueprints of kind from r. Every row is validated like
+// an uploaded blueprint; the errors of all rows are returned as a *CSVError.
+func ReadCSV(kind *blueprint.Kind, r io.Reader) ([]registry.Request, error) {
+	model, err := kindModel(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &CSVError{Kind: kind.Name, Cells: []CellError{{Row: 1, Err: errors.New("missing header")}}}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		columns = make([]column, len(header))
+		names   = make(map[string]bool, len(header))
+		failed  = &CSVError{Kind: kind.Name}
+	)
+
+	for i, name := range header {
+		// Spreadsheets tend to start the file with a byte order mark.
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+
+		// Columns without a name are notes next to the data.
+		if name == "" {
+			continue
+		}
+
+		if names[name] {
+			failed.Cells = append(failed.Cells, CellError{Row: 1, Column: name, Err: errors.New("duplicate column")})
+			continue
+		}
+
+		names[name] = true
+
+		col, err := compileColumn(model, name)
+		if err != nil {
+			failed.Cells = append(failed.Cells, CellError{Row: 1, Column: name, Err: err})
+			continue
+		}
+
+		columns[i] = col
+	}
+
+	if len(failed.Cells) > 0 {
+		return nil, failed
+	}
+
+	var (
+		defs = make([]registry.Request, 0)
+		seen = make(map[string]int)
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		row, _ := reader.FieldPos(0)
+
+		if blankRecord(record) {
+			continue
+		}
+
+		if len(record) > len(columns) {
+			failed.Cells = append(failed.Cells, CellError{Row: row, Err: fmt.Errorf("%d cells for %d columns", len(record), len(columns))})
+			continue
+		}
+
+		def, cells := readRecord(kind, columns, record, row)
+		if len(cells) > 0 {
+			failed.Cells = append(failed.Cells, cells...)
+			continue
+		}
+
+		if first, ok := seen[def.GetSlug()]; ok {
+			failed.Cells = append(failed.Cells, CellError{Row: row, Column: "slug", Err: fmt.Errorf("%s is already defined in row %d", def.GetSlug(), first)})
+			continue
+		}
+
+		seen[def.GetSlug()] = row
+		defs = append(defs, def)
+	}
+
+	if len(failed.Cells) > 0 {
+		return nil, failed
+	}
+
+	return defs, nil
+}
+
+func blankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func readRecord(kind *blueprint.Kind, columns []column, record []string, row int) (registry.Request, []CellError) {
+	var (
+		root  = newNode()
+		cells = make([]CellError, 0)
+	)
+
+	for i, raw := range record {
+		raw = strings.TrimSpace(raw)
+		if raw == "" || columns[i].leaf == nil {
+			continue
+		}
+
+		value, err := cellValue(columns[i].leaf, raw)
+		if err != nil {
+			cells = append(cells, CellError{Row: row, Column: columns[i].name, Err: err})
+			continue
+		}
+
+		root.insert(columns[i], value)
+	}
+
+	if len(cells) > 0 {
+		return nil, cells
+	}
+
+	locations := make(map[string]string)
+
+	raw, err := json.Marshal(root.emit("", locations))
+	if err != nil {
+		return nil, []CellError{{Row: row, Err: err}}
+	}
+
+	def, err := kind.DecodeBody(raw)
+	if err != nil {
+		return nil, schemaCells(err, row, locations)
+	}
+
+	if kind.Validate != nil {
+		if err := kind.Validate(def); err != nil {
+			return nil, []CellError{{Row: row, Err: err}}
+		}
+	}
+
+	return def, nil
+}
+
+// schemaCells splits a schema error into the leaf errors, pinned to the column
+// the failing value came from.
+func schemaCells(err error, row int, locations map[string]string) []CellError {
+	var validation *jsonschema.ValidationError
+	if !errors.As(err, &validation) {
+		return []CellError{{Row: row, Err: err}}
+	}
+
+	cells := make([]CellError, 0)
+
+	var walk func(v *jsonschema.ValidationError)
+	walk = func(v *jsonschema.ValidationError) {
+		if len(v.Causes) == 0 {
+			cells = append(cells, CellError{Row: row, Column: columnAt(locations, v.InstanceLocation), Err: errors.New(v.Message)})
+			return
+		}
+
+		for _, cause := range v.Causes {
+			walk(cause)
+		}
+	}
+
+	walk(validation)
+
+	return cells
+}
+
+// columnAt returns the column a JSON pointer was filled from, or of its
+// closest parent.
+func columnAt(locations map[string]string, pointer string) string {
+	for pointer != "" {
+		if name, ok := locations[pointer]; ok {
+			return name
+		}
+
+		i := strings.LastIndex(pointer, "/")
+		if i < 0 {
+			break
+		}
+
+		pointer = pointer[:i]
+	}
+
+	return ""
+}
+
+// WriteCSV encodes defs of kind to w, one row per blueprint.
+func WriteCSV(kind *blueprint.Kind, w io.Writer, defs []registry.Request) error {
+	model, err := kindModel(kind)
+	if err != nil {
+		return err
+	}
+
+	var (
+		rows    = make([]map[string]string, 0, len(defs))
+		columns = make(map[string]column)
+	)
+
+	for _, def := range defs {
+		raw, err := json.Marshal(def)
+		if err != nil {
+			return err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+
+		row := make(map[string]string)
+		flatten(model, "", value, row)
+
+		for name := range row {
+			if _, ok := columns[name]; ok {
+				continue
+			}
+
+			col, err := compileColumn(model, name)
+			if err != nil {
+				return err
+			}
+
+			columns[name] = col
+		}
+
+		rows = append(rows, row)
+	}
+
+	header := make([]column, 0, len(columns))
+	for _, col := range columns {
+		header = append(header, col)
+	}
+
+	sort.Slice(header, func(i, j int) bool { return columnLess(header[i], header[j]) })
+
+	writer := csv.NewWriter(w)
+
+	names := make([]string, len(header))
+	for i, col := range header {
+		names[i] = col.name
+	}
+
+	if err := writer.Write(names); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for i, col := range header {
+			record[i] = row[col.name]
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func kindModel(kind *blueprint.Kind) (reflect.Type, error) {
+	if kind.Model == nil {
+		return nil, fmt.Errorf("%s: kind has no model", kind.Name)
+	}
+
+	t := reflect.TypeOf(kind.Model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t, nil
+}
+
+// compileColumn resolves a column name against t.
+func compileColumn(t reflect.Type, name string) (column, error) {
+	col := column{name: name, steps: make([]step, 0)}
+	segments := strings.Split(name, ".")
+
+	for i := 0; i < len(segments); i++ {
+		segment := segments[i]
+
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			field, order, ok := fieldByJSONName(t, segment)
+			if !ok {
+				return column{}, errUnknownColumn
+			}
+
+			col.steps = append(col.steps, step{kind: stepField, name: segment, order: order})
+			t = field.Type
+		case reflect.Slice:
+			elem := t.Elem()
+			for elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+			}
+
+			if keyField, valueField, valueType, ok := amountList(elem); ok {
+				if i != len(segments)-1 || segment == "" {
+					return column{}, errUnknownColumn
+				}
+
+				col.steps = append(col.steps, step{kind: stepKey, name: segment, keyField: keyField, valueField: valueField})
+				t = valueType
+
+				continue
+			}
+
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 {
+				return column{}, errUnknownColumn
+			}
+
+			col.steps = append(col.steps, step{kind: stepIndex, index: index})
+			t = t.Elem()
+		default:
+			return column{}, errUnknownColumn
+		}
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if !scalarKind(t.Kind()) {
+		return column{}, fmt.Errorf("column doesn't name a single value")
+	}
+
+	col.leaf = t
+
+	return col, nil
+}
+
+func columnLess(a, b column) bool {
+	for i := 0; i < len(a.steps) && i < len(b.steps); i++ {
+		sa, sb := a.steps[i], b.steps[i]
+
+		switch {
+		case sa.kind != sb.kind:
+			return sa.kind < sb.kind
+		case sa.kind == stepField && sa.order != sb.order:
+			return sa.order < sb.order
+		case sa.kind == stepIndex && sa.index != sb.index:
+			return sa.index < sb.index
+		case sa.kind == stepKey && sa.name != sb.name:
+			return sa.name < sb.name
+		}
+	}
+
+	return len(a.steps) < len(b.steps)
+}
+
+// amountList reports whether items of type t are a string key and a number,
+// like the resource and amount of a cost.
+func amountList(t reflect.Type) (keyField, valueField string, valueType reflect.Type, ok bool) {
+	if t.Kind() != reflect.Struct {
+		return "", "", nil, false
+	}
+
+	fields := exportedFields(t)
+	if len(fields) != 2 {
+		return "", "", nil, false
+	}
+
+	for i, field := range fields {
+		other := fields[1-i]
+
+		if field.Type.Kind() == reflect.String && numberKind(other.Type.Kind()) {
+			return fieldName(field), fieldName(other), other.Type, true
+		}
+	}
+
+	return "", "", nil, false
+}
+
+func exportedFields(t reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || fieldName(field) == "-" {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, int, bool) {
+	for i, field := range exportedFields(t) {
+		if fieldName(field) == name {
+			return field, i, true
+		}
+	}
+
+	return reflect.StructField{}, 0, false
+}
+
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+func scalarKind(kind reflect.Kind) bool {
+	return kind == reflect.String || kind == reflect.Bool || numberKind(kind)
+}
+
+func numberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// cellValue converts a cell to the JSON value of a field of type t.
+func cellValue(t reflect.Type, raw string) (any, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := strconv.ParseInt(raw, 10, t.Bits()); err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+
+		return json.Number(raw), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseUint(raw, 10, t.Bits()); err != nil {
+			return nil, fmt.Errorf("%q is not a positive integer", raw)
+		}
+
+		return json.Number(raw), nil
+	default:
+		if _, err := strconv.ParseFloat(raw, t.Bits()); err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+
+		return json.Number(raw), nil
+	}
+}
+
+// node builds the JSON value of a row from its cells.
+type node struct {
+	column string
+	value  any
+
+	fields map[string]*node
+	order  []string
+
+	items map[int]*node
+
+	key        string
+	keyField   string
+	valueField string
+	keys       []*node
+}
+
+func newNode() *node {
+	return &node{}
+}
+
+func (n *node) insert(col column, value any) {
+	current := n
+
+	for _, s := range col.steps {
+		switch s.kind {
+		case stepField:
+			if current.fields == nil {
+				current.fields = make(map[string]*node)
+			}
+
+			next, ok := current.fields[s.name]
+			if !ok {
+				next = newNode()
+				current.fields[s.name] = next
+				current.order = append(current.order, s.name)
+			}
+
+			current = next
+		case stepIndex:
+			if current.items == nil {
+				current.items = make(map[int]*node)
+			}
+
+			next, ok := current.items[s.index]
+			if !ok {
+				next = newNode()
+				current.items[s.index] = next
+			}
+
+			current = next
+		case stepKey:
+			current.keyField = s.keyField
+			current.valueField = s.valueField
+			current.keys = append(current.keys, &node{column: col.name, value: value, key: s.name})
+
+			return
+		}
+	}
+
+	current.column = col.name
+	current.value = value
+}
+
+// emit returns the JSON value of n and records the column every JSON pointer
+// was filled from.
+func (n *node) emit(pointer string, locations map[string]string) any {
+	switch {
+	case n.keys != nil:
+		list := make([]any, 0, len(n.keys))
+
+		for i, key := range n.keys {
+			item := fmt.Sprintf("%s/%d", pointer, i)
+			locations[item] = key.column
+			locations[item+"/"+n.valueField] = key.column
+
+			list = append(list, map[string]any{n.keyField: key.key, n.valueField: key.value})
+		}
+
+		return list
+	case n.items != nil:
+		indexes := make([]int, 0, len(n.items))
+		for index := range n.items {
+			indexes = append(indexes, index)
+		}
+
+		sort.Ints(indexes)
+
+		list := make([]any, 0, len(indexes))
+		for i, index := range indexes {
+			list = append(list, n.items[index].emit(fmt.Sprintf("%s/%d", pointer, i), locations))
+		}
+
+		return list
+	case n.fields != nil:
+		object := make(map[string]any, len(n.fields))
+		for _, name := range n.order {
+			object[name] = n.fields[name].emit(pointer+"/"+name, locations)
+		}
+
+		return object
+	default:
+		locations[pointer] = n.column
+
+		return n.value
+	}
+}
+
+// flatten writes the cells of value, the JSON encoding of a value of type t,
+// to row.
+func flatten(t reflect.Type, prefix string, value any, row map[string]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+
+		return prefix + "." + name
+	}
+
+	switch v := value.(type) {
+	case nil:
+	case map[string]any:
+		if t.Kind() != reflect.Struct {
+			return
+		}
+
+		for _, field := range exportedFields(t) {
+			name := fieldName(field)
+			flatten(field.Type, join(name), v[name], row)
+		}
+	case []any:
+		if t.Kind() != reflect.Slice {
+			return
+		}
+
+		elem := t.Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+
+		if keyField, valueField, _, ok := amountList(elem); ok {
+			for _, item := range v {
+				if object, ok := item.(map[string]any); ok {
+					if key, ok := object[keyField].(string); ok && key != "" {
+						row[join(key)] = cellString(object[valueField])
+					}
+				}
+			}
+
+			return
+		}
+
+		for i, item := range v {
+			flatten(t.Elem(), join(strconv.Itoa(i)), item, row)
+		}
+	default:
+		if cell := cellString(v); cell != "" {
+			row[prefix] = cell
+		}
+	}
+}
+
+func cellString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
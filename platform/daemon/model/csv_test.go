@@ -0,0 +1,120 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var csvBuildings = []registry.Request{
+	registry.BuildingBlueprintRequest{
+		Name:      "House",
+		Slug:      "house",
+		BuildTime: "10s",
+		Cost: registry.ResourceList{
+			{Resource: "wood", Amount: 10},
+			{Resource: "stone", Amount: 5},
+		},
+	},
+	registry.BuildingBlueprintRequest{
+		Name:      "Sawmill",
+		Slug:      "sawmill",
+		BuildTime: "1m0s",
+		Cost:      registry.ResourceList{{Resource: "wood", Amount: 20}},
+		Production: []registry.Production{
+			{
+				Cost:           registry.ResourceList{{Resource: "wood", Amount: 2}},
+				Product:        registry.ResourceList{{Resource: "plank", Amount: 1}},
+				ProductionTime: "5s",
+			},
+		},
+	},
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	kind, err := blueprint.Get(KindBuilding)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteCSV(kind, buf, csvBuildings))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "name,slug,build_time,cost.stone,cost.wood,production.0.cost.wood,production.0.product.plank,production.0.production_time", lines[0])
+	assert.Equal(t, "House,house,10s,5,10,,,", lines[1])
+
+	defs, err := ReadCSV(kind, buf)
+	require.NoError(t, err)
+	require.Len(t, defs, 2)
+
+	house := defs[0].(registry.BuildingBlueprintRequest)
+	assert.Equal(t, "house", house.Slug)
+	assert.ElementsMatch(t, csvBuildings[0].(registry.BuildingBlueprintRequest).Cost, house.Cost)
+	assert.Empty(t, house.Production)
+
+	assert.Equal(t, csvBuildings[1], defs[1])
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	kind, err := blueprint.Get(KindBuilding)
+	require.NoError(t, err)
+
+	tests := []struct {
+		label    string
+		input    string
+		expected []CellError
+	}{
+		{
+			label:    "unknown column",
+			input:    "name,slug,build_time,colour\n",
+			expected: []CellError{{Row: 1, Column: "colour"}},
+		},
+		{
+			label:    "not a list of amounts",
+			input:    "name,slug,build_time,cost\n",
+			expected: []CellError{{Row: 1, Column: "cost"}},
+		},
+		{
+			label: "rows",
+			input: "\ufeffname,slug,build_time,cost.wood,production.1.production_time,\n" +
+				"House,house,10s,10,,note\n" +
+				"Hut,hut,soon,-1,,\n" +
+				",,,,,\n" +
+				"Farm,farm,10s,,later,\n" +
+				"Barn,,10s,,,\n" +
+				"Home,house,5s,,,\n",
+			expected: []CellError{
+				{Row: 3, Column: "cost.wood"},
+				{Row: 5, Column: "production.1.production_time"},
+				{Row: 6, Column: ""},
+				{Row: 7, Column: "slug"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			_, err := ReadCSV(kind, strings.NewReader(tt.input))
+
+			var csvErr *CSVError
+			require.True(t, errors.As(err, &csvErr), "expected a CSV error, got %v", err)
+			assert.Equal(t, KindBuilding, csvErr.Kind)
+
+			actual := make([]CellError, 0, len(csvErr.Cells))
+			for _, cell := range csvErr.Cells {
+				assert.Error(t, cell.Err)
+				actual = append(actual, CellError{Row: cell.Row, Column: cell.Column})
+			}
+
+			assert.Equal(t, tt.expected, actual)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
@@ -0,0 +1,174 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is a blueprint that differs between two batches.
+type Change struct {
+	Kind string
+	Slug string
+	Op   string
+}
+
+// Diff lists the blueprints that have to be added, removed or changed to turn
+// remote into local. Durations are compared by value and empty lists equal
+// missing ones, so a version exported from the registry matches the files it
+// was uploaded from. Display text counts as part of the blueprint.
+func Diff(local, remote *BlueprintBatchRequest) ([]Change, error) {
+	localDefs, err := definitionsBySlug(local)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteDefs, err := definitionsBySlug(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+
+	for key, def := range localDefs {
+		other, ok := remoteDefs[key]
+
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: key.kind, Slug: key.slug, Op: ChangeAdded})
+		case !reflect.DeepEqual(def, other):
+			changes = append(changes, Change{Kind: key.kind, Slug: key.slug, Op: ChangeChanged})
+		}
+	}
+
+	for key := range remoteDefs {
+		if _, ok := localDefs[key]; !ok {
+			changes = append(changes, Change{Kind: key.kind, Slug: key.slug, Op: ChangeRemoved})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+
+		return changes[i].Slug < changes[j].Slug
+	})
+
+	return changes, nil
+}
+
+type diffKey struct {
+	kind string
+	slug string
+}
+
+func definitionsBySlug(batch *BlueprintBatchRequest) (map[diffKey]any, error) {
+	defs := make(map[diffKey]any)
+
+	if batch == nil {
+		return defs, nil
+	}
+
+	for name, items := range batch.Blueprints {
+		durations := make(map[string]bool)
+
+		if kind, err := blueprint.Get(name); err == nil {
+			for _, field := range kind.Durations {
+				durations[field] = true
+			}
+		}
+
+		for _, def := range items {
+			item, err := withLocales(def, batch.Locales[name][def.GetSlug()])
+			if err != nil {
+				return nil, err
+			}
+
+			normalized, err := normalizeDefinition(item, durations)
+			if err != nil {
+				return nil, err
+			}
+
+			defs[diffKey{kind: name, slug: def.GetSlug()}] = normalized
+		}
+	}
+
+	return defs, nil
+}
+
+// NormalizeDefinition decodes def of the named kind to plain JSON values the
+// way Diff compares them, with durations in canonical form.
+func NormalizeDefinition(kind string, def any) (any, error) {
+	durations := make(map[string]bool)
+
+	if registered, err := blueprint.Get(kind); err == nil {
+		for _, field := range registered.Durations {
+			durations[field] = true
+		}
+	}
+
+	return normalizeDefinition(def, durations)
+}
+
+func normalizeDefinition(def any, durations map[string]bool) (any, error) {
+	raw, err := json.Marshal(def)
+	if err != nil {
+		return nil, err
+	}
+
+	var tmp any
+	if err := json.Unmarshal(raw, &tmp); err != nil {
+		return nil, err
+	}
+
+	return normalizeValue(tmp, durations), nil
+}
+
+func normalizeValue(v any, durations map[string]bool) any {
+	switch value := v.(type) {
+	case map[string]any:
+		normalized := make(map[string]any, len(value))
+
+		for key, item := range value {
+			if durations[key] {
+				if raw, ok := item.(string); ok {
+					if d, err := time.ParseDuration(raw); err == nil {
+						item = d.String()
+					}
+				}
+			}
+
+			item = normalizeValue(item, durations)
+			if item == nil {
+				continue
+			}
+
+			normalized[key] = item
+		}
+
+		return normalized
+	case []any:
+		if len(value) == 0 {
+			return nil
+		}
+
+		normalized := make([]any, 0, len(value))
+		for _, item := range value {
+			normalized = append(normalized, normalizeValue(item, durations))
+		}
+
+		return normalized
+	default:
+		return v
+	}
+}
@@ -0,0 +1,218 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"gopkg.in/yaml.v3"
+)
+
+// DirEntry is a blueprint read from a file of a blueprint directory. Path is
+// relative to the directory, with the document index appended for files
+// holding more than one blueprint.
+type DirEntry struct {
+	Path string
+	BlueprintRequest
+}
+
+var blueprintExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+// ReadDir reads every YAML and JSON file below dir. A file may hold several
+// blueprints as separate YAML documents. Files that fail to decode are
+// reported in the returned error, the blueprints read from the other files
+// are still returned.
+func ReadDir(dir string) ([]DirEntry, error) {
+	var (
+		entries = make([]DirEntry, 0)
+		errs    = make([]error, 0)
+	)
+
+	walk := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !blueprintExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		read, err := readFile(path, filepath.ToSlash(rel))
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		entries = append(entries, read...)
+
+		return nil
+	}
+
+	if err := filepath.WalkDir(dir, walk); err != nil {
+		return nil, err
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+func readFile(path, rel string) ([]DirEntry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		entries = make([]DirEntry, 0, 1)
+		decoder = yaml.NewDecoder(bytes.NewReader(raw))
+	)
+
+	for i := 1; ; i++ {
+		var req BlueprintRequest
+
+		err := decoder.Decode(&req)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		source := rel
+		if i > 1 {
+			source = fmt.Sprintf("%s#%d", rel, i)
+		}
+
+		if err != nil {
+			return entries, fmt.Errorf("%s: %w", source, err)
+		}
+
+		entries = append(entries, DirEntry{Path: source, BlueprintRequest: req})
+	}
+
+	if len(entries) > 1 {
+		entries[0].Path += "#1"
+	}
+
+	return entries, nil
+}
+
+// Lint checks entries with the rules the upload handlers apply, and that no
+// blueprint is defined twice. Versions are optional, but have to be semantic
+// versions if set.
+func Lint(entries []DirEntry) error {
+	var (
+		errs = make([]error, 0)
+		seen = make(map[string]string)
+	)
+
+	for _, entry := range entries {
+		kind, err := blueprint.Get(entry.Kind)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Path, err))
+			continue
+		}
+
+		if entry.Version != "" {
+			if _, err := metadata.ParseVersion(entry.Version); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", entry.Path, err))
+				continue
+			}
+		}
+
+		// Templates are only complete once resolved against their base.
+		if entry.Extends != "" {
+			_, err = NewTemplate(entry.Extends, entry.Overrides)
+		} else {
+			err = kind.ValidateDefinition(entry.Definition)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Path, err))
+			continue
+		}
+
+		key := entry.Kind + "/" + entry.Slug()
+		if first, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("%s: %s is already defined in %s", entry.Path, key, first))
+			continue
+		}
+
+		seen[key] = entry.Path
+	}
+
+	return errors.Join(errs...)
+}
+
+// BatchFromEntries collects entries into a single batch. An empty version
+// takes the version of the entries, which then have to agree on it.
+func BatchFromEntries(version string, entries []DirEntry) (*BlueprintBatchRequest, error) {
+	batch := &BlueprintBatchRequest{Version: version}
+
+	for _, entry := range entries {
+		if version == "" && entry.Version != "" {
+			if batch.Version != "" && batch.Version != entry.Version {
+				return nil, fmt.Errorf("%s: version %s differs from %s", entry.Path, entry.Version, batch.Version)
+			}
+
+			batch.Version = entry.Version
+		}
+
+		if err := batch.Add(entry.BlueprintRequest); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Path, err)
+		}
+	}
+
+	if batch.Version == "" {
+		return nil, errors.New("no version given")
+	}
+
+	return batch, nil
+}
+
+// WriteDir writes the batch to dir in the layout of WriteTarGz, one YAML file
+// per blueprint.
+func WriteDir(dir string, batch *BlueprintBatchRequest) error {
+	requests := batch.Requests()
+
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].FileName() < requests[j].FileName() })
+
+	for _, req := range requests {
+		raw, err := yaml.Marshal(req)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(req.FileName()))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(path, raw, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, WriteDir(dir, testBatch))
+	assert.FileExists(t, filepath.Join(dir, "building", "house.yaml"))
+	assert.FileExists(t, filepath.Join(dir, "resource", "wood.yaml"))
+
+	entries, err := ReadDir(dir)
+	require.NoError(t, err)
+	require.NoError(t, Lint(entries))
+
+	batch, err := BatchFromEntries("", entries)
+	require.NoError(t, err)
+
+	changes, err := Diff(batch, testBatch)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestReadDirErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"resources.yaml": "kind: resource\nversion: 1.0.0\nbody:\n  name: Wood\n  slug: wood\n---\nkind: resource\nversion: 1.0.0\nbody:\n  name: Stone\n  slug: stone\n",
+		"duplicate.yaml": "kind: resource\nversion: 1.0.0\nbody:\n  name: Wood\n  slug: wood\n",
+		"invalid.yaml":   "kind: building\nversion: 1.0.0\nbody:\n  name: House\n  slug: house\n  build_time: soon\n",
+		"unknown.yaml":   "kind: unit\nversion: 1.0.0\nbody:\n  name: Archer\n",
+		"nightly.yaml":   "kind: resource\nversion: nightly\nbody:\n  name: Stone\n  slug: stone\n",
+		"notes.txt":      "not a blueprint",
+		".git/HEAD.yaml": "{",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	entries, err := ReadDir(dir)
+	assert.ErrorAs(t, err, &blueprint.SchemaError{})
+	assert.ErrorContains(t, err, "invalid.yaml")
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+
+	assert.ElementsMatch(t, []string{"duplicate.yaml", "nightly.yaml", "resources.yaml#1", "resources.yaml#2", "unknown.yaml"}, paths)
+
+	err = Lint(entries)
+	assert.ErrorIs(t, err, blueprint.ErrUnknownKind)
+	assert.ErrorIs(t, err, metadata.ErrInvalidVersion)
+	assert.ErrorContains(t, err, "resource/wood is already defined in duplicate.yaml")
+}
+
+func TestBatchFromEntries(t *testing.T) {
+	entries := []DirEntry{
+		{Path: "a.yaml", BlueprintRequest: BlueprintRequest{Kind: KindResource, Version: "1.0.0", Definition: registry.ResourceBlueprintRequest{Slug: "a"}}},
+		{Path: "b.yaml", BlueprintRequest: BlueprintRequest{Kind: KindResource, Version: "2.0.0", Definition: registry.ResourceBlueprintRequest{Slug: "b"}}},
+	}
+
+	_, err := BatchFromEntries("", entries)
+	assert.ErrorContains(t, err, "b.yaml: version 2.0.0 differs from 1.0.0")
+
+	batch, err := BatchFromEntries("3.0.0", entries)
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.0", batch.Version)
+	assert.Equal(t, 2, batch.Len())
+}
+
+func TestDiff(t *testing.T) {
+	local := &BlueprintBatchRequest{
+		Version: "1.0.0",
+		Blueprints: map[string][]registry.Request{
+			KindBuilding: {
+				registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "90s"},
+				registry.BuildingBlueprintRequest{Name: "Farm", Slug: "farm", BuildTime: "30s"},
+				registry.BuildingBlueprintRequest{Name: "Mill", Slug: "mill", BuildTime: "30s"},
+			},
+		},
+	}
+
+	remote := &BlueprintBatchRequest{
+		Version: "1.0.0",
+		Blueprints: map[string][]registry.Request{
+			KindBuilding: {
+				registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "1m30s", Cost: registry.ResourceList{}},
+				registry.BuildingBlueprintRequest{Name: "Farm", Slug: "farm", BuildTime: "1m"},
+			},
+			KindResource: {
+				registry.ResourceBlueprintRequest{Name: "Wood", Slug: "wood"},
+			},
+		},
+	}
+
+	changes, err := Diff(local, remote)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Change{
+		{Kind: KindBuilding, Slug: "farm", Op: ChangeChanged},
+		{Kind: KindBuilding, Slug: "mill", Op: ChangeAdded},
+		{Kind: KindResource, Slug: "wood", Op: ChangeRemoved},
+	}, changes)
+}
@@ -1,5 +1,11 @@
 package model
 
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
 func getString(m map[string]interface{}, field string) (string, bool) {
 	r, ok := m[field]
 	if !ok {
@@ -27,3 +33,55 @@ func getString(m map[string]interface{}, field string) (string, bool) {
 
 // 	return rr, true
 // }
+
+// yamlNode renders v through its JSON encoding, so YAML documents use the
+// same field names as the JSON API.
+func yamlNode(v any) (*yaml.Node, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	node := doc.Content[0]
+	resetStyle(node)
+
+	return node, nil
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// decodeYAMLAsJSON decodes a YAML node into v through its JSON encoding.
+func decodeYAMLAsJSON(x *yaml.Node, v any) error {
+	var tmp any
+	if err := x.Decode(&tmp); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(tmp)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// ToYAML renders v as YAML with the field names of its JSON encoding.
+func ToYAML(v any) ([]byte, error) {
+	node, err := yamlNode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(node)
+}
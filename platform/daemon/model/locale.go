@@ -0,0 +1,96 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"golang.org/x/text/language"
+)
+
+// localesField is the key of the display text in uploaded blueprints. The
+// registry doesn't store it, it is kept with the gateway's annotations.
+const localesField = "locales"
+
+// ValidateLocales checks that every locale is a well-formed language tag.
+func ValidateLocales(locales metadata.Locales) error {
+	for tag := range locales {
+		if _, err := language.Parse(tag); err != nil {
+			return fmt.Errorf("invalid locale %q: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// splitLocales removes the display text from a batch item.
+func splitLocales(item json.RawMessage) (json.RawMessage, metadata.Locales, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(item, &fields); err != nil {
+		return nil, nil, err
+	}
+
+	raw, ok := fields[localesField]
+	if !ok {
+		return item, nil, nil
+	}
+
+	var locales metadata.Locales
+	if err := json.Unmarshal(raw, &locales); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", localesField, err)
+	}
+
+	if err := ValidateLocales(locales); err != nil {
+		return nil, nil, err
+	}
+
+	delete(fields, localesField)
+
+	item, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return item, locales, nil
+}
+
+// withLocales adds the display text to a batch item.
+func withLocales(item any, locales metadata.Locales) (any, error) {
+	if len(locales) == 0 {
+		return item, nil
+	}
+
+	fields, ok := item.(map[string]any)
+	if !ok {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = make(map[string]any)
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, err
+		}
+	}
+
+	fields[localesField] = locales
+
+	return fields, nil
+}
+
+// SetLocales records the display text of a blueprint of the batch.
+func (b *BlueprintBatchRequest) SetLocales(kind, slug string, locales metadata.Locales) {
+	if len(locales) == 0 {
+		return
+	}
+
+	if b.Locales == nil {
+		b.Locales = make(map[string]map[string]metadata.Locales)
+	}
+
+	if b.Locales[kind] == nil {
+		b.Locales[kind] = make(map[string]metadata.Locales)
+	}
+
+	b.Locales[kind][slug] = locales
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchLocales(t *testing.T) {
+	body := `{"version": "1.0.0", "resources": [
+		{"name": "Wood", "slug": "wood", "locales": {"en": {"name": "Wood"}, "de": {"name": "Holz", "description": "Zum Bauen"}}},
+		{"name": "Stone", "slug": "stone"}
+	]}`
+
+	var batch BlueprintBatchRequest
+	require.NoError(t, json.Unmarshal([]byte(body), &batch))
+	require.Len(t, batch.Blueprints[KindResource], 2)
+
+	expected := metadata.Locales{
+		"en": {Name: "Wood"},
+		"de": {Name: "Holz", Description: "Zum Bauen"},
+	}
+	assert.Equal(t, expected, batch.Locales[KindResource]["wood"])
+
+	for _, req := range batch.Requests() {
+		if req.Slug() == "wood" {
+			assert.Equal(t, expected, req.Locales)
+		} else {
+			assert.Empty(t, req.Locales)
+		}
+	}
+
+	raw, err := json.Marshal(batch)
+	require.NoError(t, err)
+
+	var roundTrip BlueprintBatchRequest
+	require.NoError(t, json.Unmarshal(raw, &roundTrip))
+	assert.Equal(t, batch.Locales, roundTrip.Locales)
+}
+
+func TestValidateLocales(t *testing.T) {
+	assert.NoError(t, ValidateLocales(metadata.Locales{"en": {}, "pt-BR": {}, "zh-Hant": {}}))
+	assert.Error(t, ValidateLocales(metadata.Locales{"not a tag": {}}))
+
+	body := `{"version": "1.0.0", "resources": [{"name": "Wood", "slug": "wood", "locales": {"?": {"name": "Wood"}}}]}`
+
+	var batch BlueprintBatchRequest
+	assert.Error(t, json.Unmarshal([]byte(body), &batch))
+}
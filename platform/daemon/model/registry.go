@@ -2,80 +2,236 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
 
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
 	"github.com/GnarloqGames/genesis-avalon-kit/registry"
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	KindBuilding = "building"
-	KindResource = "resource"
+	KindBuilding = blueprint.KindBuilding
+	KindResource = blueprint.KindResource
 )
 
+// BlueprintRequest carries a single blueprint. A request that extends
+// another blueprint carries the overrides as its body, and has no definition
+// until it is resolved.
 type BlueprintRequest struct {
 	Kind       string           `json:"kind"`
 	Version    string           `json:"version"`
 	Force      bool             `json:"force"`
+	Extends    string           `json:"extends,omitempty"`
 	Definition registry.Request `json:"body"`
+	Locales    metadata.Locales `json:"locales,omitempty"`
+	Overrides  json.RawMessage  `json:"-"`
 }
 
+// BlueprintBatchRequest carries blueprints of any registered kind, keyed by
+// kind name. On the wire each kind is listed under its collection name, for
+// example buildings or resources. Items with an extends field are templates,
+// they are added to the blueprints by Resolve. Display text in the locales
+// field of items is kept by kind and slug.
 type BlueprintBatchRequest struct {
-	Version   string                              `json:"version"`
-	Force     bool                                `json:"force"`
-	Buildings []registry.BuildingBlueprintRequest `json:"buildings"`
-	Resources []registry.ResourceBlueprintRequest `json:"resources"`
+	Version    string
+	Force      bool
+	Blueprints map[string][]registry.Request
+	Templates  map[string][]Template
+	Locales    map[string]map[string]metadata.Locales
 }
 
-func (b *BlueprintRequest) UnmarshalJSON(d []byte) error {
-	tmp := make(map[string]interface{})
-	if err := json.Unmarshal(d, &tmp); err != nil {
-		return err
+func (b BlueprintRequest) MarshalYAML() (interface{}, error) {
+	return yamlNode(b)
+}
+
+func (b BlueprintBatchRequest) MarshalYAML() (interface{}, error) {
+	return yamlNode(b)
+}
+
+func (b *BlueprintBatchRequest) UnmarshalYAML(x *yaml.Node) error {
+	return decodeYAMLAsJSON(x, b)
+}
+
+// MarshalJSON writes requests extending another blueprint in their compact
+// form, with the overrides as body.
+func (b BlueprintRequest) MarshalJSON() ([]byte, error) {
+	type request BlueprintRequest
+
+	if b.Extends == "" {
+		return json.Marshal(request(b))
 	}
 
-	if kind, ok := getString(tmp, "kind"); ok {
-		b.Kind = kind
+	return json.Marshal(struct {
+		request
+		Body json.RawMessage `json:"body"`
+	}{
+		request: request(b),
+		Body:    b.Overrides,
+	})
+}
+
+func (b BlueprintBatchRequest) MarshalJSON() ([]byte, error) {
+	tmp := map[string]any{
+		"version": b.Version,
+		"force":   b.Force,
 	}
 
-	if version, ok := getString(tmp, "version"); ok {
-		b.Version = version
+	names := make(map[string]bool)
+	for name := range b.Blueprints {
+		names[name] = true
 	}
 
-	rawBody := []byte("{}")
+	for name := range b.Templates {
+		names[name] = true
+	}
 
-	if body, ok := tmp["body"].(map[string]interface{}); ok {
-		if raw, err := json.Marshal(body); err != nil {
-			return err
-		} else {
-			rawBody = raw
+	for name := range names {
+		key := name
+		if kind, err := blueprint.Get(name); err == nil {
+			key = kind.Collection
 		}
-	}
 
-	switch b.Kind {
-	case KindBuilding:
-		var breq registry.BuildingBlueprintRequest
-		if err := json.Unmarshal(rawBody, &breq); err != nil {
-			return err
+		templates := make(map[string]Template)
+		for _, template := range b.Templates[name] {
+			templates[template.Slug] = template
 		}
 
-		b.Definition = registry.Request(breq)
-	case KindResource:
-		var rreq registry.ResourceBlueprintRequest
-		if err := json.Unmarshal(rawBody, &rreq); err != nil {
-			return err
+		items := make([]any, 0, len(b.Blueprints[name])+len(templates))
+
+		for _, def := range b.Blueprints[name] {
+			var item any = def
+
+			if template, ok := templates[def.GetSlug()]; ok {
+				compact, err := template.compact()
+				if err != nil {
+					return nil, err
+				}
+
+				item = compact
+				delete(templates, def.GetSlug())
+			}
+
+			item, err := withLocales(item, b.Locales[name][def.GetSlug()])
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		// Templates that were never resolved keep their order in the batch.
+		for _, template := range b.Templates[name] {
+			if _, ok := templates[template.Slug]; !ok {
+				continue
+			}
+
+			compact, err := template.compact()
+			if err != nil {
+				return nil, err
+			}
+
+			item, err := withLocales(compact, b.Locales[name][template.Slug])
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
 		}
 
-		b.Definition = registry.Request(rreq)
+		tmp[key] = items
+	}
+
+	return json.Marshal(tmp)
+}
+
+func (b *BlueprintBatchRequest) UnmarshalJSON(d []byte) error {
+	tmp := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(d, &tmp); err != nil {
+		return err
+	}
+
+	*b = BlueprintBatchRequest{
+		Blueprints: make(map[string][]registry.Request),
+	}
+
+	for key, raw := range tmp {
+		switch key {
+		case "version":
+			if err := json.Unmarshal(raw, &b.Version); err != nil {
+				return err
+			}
+		case "force":
+			if err := json.Unmarshal(raw, &b.Force); err != nil {
+				return err
+			}
+		default:
+			kind, err := blueprint.Find(key)
+			if err != nil {
+				return err
+			}
+
+			var items []json.RawMessage
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+
+			for _, item := range items {
+				item, locales, err := splitLocales(item)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+
+				template, ok, err := decodeTemplate(item)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+
+				if ok {
+					if b.Templates == nil {
+						b.Templates = make(map[string][]Template)
+					}
+
+					b.Templates[kind.Name] = append(b.Templates[kind.Name], template)
+					b.SetLocales(kind.Name, template.Slug, locales)
+
+					continue
+				}
+
+				def, err := kind.DecodeBody(item)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+
+				b.SetLocales(kind.Name, def.GetSlug(), locales)
+
+				b.Blueprints[kind.Name] = append(b.Blueprints[kind.Name], def)
+			}
+		}
 	}
 
 	return nil
 }
 
+func (b *BlueprintRequest) UnmarshalJSON(d []byte) error {
+	tmp := make(map[string]interface{})
+	if err := json.Unmarshal(d, &tmp); err != nil {
+		return err
+	}
+
+	return b.fromMap(tmp)
+}
+
 func (b *BlueprintRequest) UnmarshalYAML(x *yaml.Node) error {
 	tmp := make(map[string]interface{})
 	if err := x.Decode(&tmp); err != nil {
 		return err
 	}
 
+	return b.fromMap(tmp)
+}
+
+func (b *BlueprintRequest) fromMap(tmp map[string]interface{}) error {
 	if kind, ok := getString(tmp, "kind"); ok {
 		b.Kind = kind
 	}
@@ -84,6 +240,21 @@ func (b *BlueprintRequest) UnmarshalYAML(x *yaml.Node) error {
 		b.Version = version
 	}
 
+	if locales, ok := tmp[localesField]; ok {
+		raw, err := json.Marshal(locales)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(raw, &b.Locales); err != nil {
+			return fmt.Errorf("%s: %w", localesField, err)
+		}
+
+		if err := ValidateLocales(b.Locales); err != nil {
+			return err
+		}
+	}
+
 	rawBody := []byte("{}")
 
 	if body, ok := tmp["body"].(map[string]interface{}); ok {
@@ -94,22 +265,59 @@ func (b *BlueprintRequest) UnmarshalYAML(x *yaml.Node) error {
 		}
 	}
 
-	switch b.Kind {
-	case KindBuilding:
-		var breq registry.BuildingBlueprintRequest
-		if err := json.Unmarshal(rawBody, &breq); err != nil {
-			return err
-		}
+	// Definitions extending another blueprint are resolved by the handlers,
+	// against the registry.
+	if extends, ok := getString(tmp, "extends"); ok && extends != "" {
+		b.Extends = extends
+		b.Overrides = rawBody
 
-		b.Definition = registry.Request(breq)
-	case KindResource:
-		var rreq registry.ResourceBlueprintRequest
-		if err := json.Unmarshal(rawBody, &rreq); err != nil {
-			return err
-		}
+		return nil
+	}
+
+	// Unknown kinds are left without a definition and rejected by the handlers.
+	kind, err := blueprint.Get(b.Kind)
+	if err != nil {
+		return nil
+	}
 
-		b.Definition = registry.Request(rreq)
+	def, err := kind.DecodeBody(rawBody)
+	if err != nil {
+		return err
 	}
 
+	b.Definition = def
+
 	return nil
 }
+
+// decodeTemplate reads a batch item with an extends field as a template.
+func decodeTemplate(item json.RawMessage) (Template, bool, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(item, &fields); err != nil {
+		return Template{}, false, err
+	}
+
+	raw, ok := fields["extends"]
+	if !ok {
+		return Template{}, false, nil
+	}
+
+	var extends string
+	if err := json.Unmarshal(raw, &extends); err != nil {
+		return Template{}, false, fmt.Errorf("extends: %w", err)
+	}
+
+	delete(fields, "extends")
+
+	overrides, err := json.Marshal(fields)
+	if err != nil {
+		return Template{}, false, err
+	}
+
+	template, err := NewTemplate(extends, overrides)
+	if err != nil {
+		return Template{}, false, err
+	}
+
+	return template, true, nil
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxSimulationAmount caps the amount of a simulation target, a plan for more
+// copies than that says nothing a smaller one doesn't.
+const MaxSimulationAmount = 1_000_000
+
+// SimulationRequest asks how a version's economy gets to a target: how long
+// it takes and which resources it costs. Available resources are in stock
+// from the start.
+type SimulationRequest struct {
+	Version   string            `json:"version"`
+	Target    SimulationTarget  `json:"target"`
+	Available map[string]uint64 `json:"available,omitempty"`
+}
+
+// SimulationTarget is an amount of a building or a resource. The amount of a
+// building is the number of copies to build, and defaults to one.
+type SimulationTarget struct {
+	Kind   string `json:"kind"`
+	Slug   string `json:"slug"`
+	Amount uint64 `json:"amount,omitempty"`
+}
+
+func (s *SimulationRequest) UnmarshalYAML(x *yaml.Node) error {
+	return decodeYAMLAsJSON(x, s)
+}
+
+// Validate checks the request and applies the default amount.
+func (s *SimulationRequest) Validate() error {
+	if s.Version == "" {
+		return errors.New("missing version")
+	}
+
+	if s.Target.Kind != KindBuilding && s.Target.Kind != KindResource {
+		return fmt.Errorf("invalid target kind: %q", s.Target.Kind)
+	}
+
+	if s.Target.Slug == "" {
+		return errors.New("missing target slug")
+	}
+
+	if s.Target.Amount == 0 {
+		s.Target.Amount = 1
+	}
+
+	if s.Target.Amount > MaxSimulationAmount {
+		return fmt.Errorf("target amount exceeds %d", MaxSimulationAmount)
+	}
+
+	return nil
+}
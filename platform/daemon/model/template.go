@@ -0,0 +1,250 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+)
+
+var (
+	ErrInheritanceCycle = errors.New("inheritance cycle")
+	ErrBaseNotFound     = errors.New("base blueprint not found")
+	ErrInvalidTemplate  = errors.New("invalid template")
+)
+
+// Template is a blueprint declared as overrides of another blueprint of the
+// same kind. The definition is the base with the overrides merged onto it as
+// a JSON merge patch: objects are merged, everything else is replaced and null
+// removes a field.
+type Template struct {
+	Slug      string          `json:"slug"`
+	Extends   string          `json:"extends"`
+	Overrides json.RawMessage `json:"overrides"`
+}
+
+// NewTemplate reads the slug of the blueprint from its overrides.
+func NewTemplate(extends string, overrides json.RawMessage) (Template, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(overrides, &fields); err != nil {
+		return Template{}, fmt.Errorf("%w: %s", ErrInvalidTemplate, err)
+	}
+
+	slug, _ := fields["slug"].(string)
+	if slug == "" {
+		return Template{}, fmt.Errorf("%w: overrides need a slug", ErrInvalidTemplate)
+	}
+
+	if extends == "" || extends == slug {
+		return Template{}, fmt.Errorf("%w: %s extends %q", ErrInvalidTemplate, slug, extends)
+	}
+
+	return Template{Slug: slug, Extends: extends, Overrides: overrides}, nil
+}
+
+// compact returns the overrides with the extends field, the form templates
+// take in batches.
+func (t Template) compact() (map[string]any, error) {
+	fields := make(map[string]any)
+	if err := json.Unmarshal(t.Overrides, &fields); err != nil {
+		return nil, err
+	}
+
+	fields["extends"] = t.Extends
+
+	return fields, nil
+}
+
+// Template returns the template a blueprint of the batch is created from.
+func (b *BlueprintBatchRequest) Template(kind, slug string) (Template, bool) {
+	for _, template := range b.Templates[kind] {
+		if template.Slug == slug {
+			return template, true
+		}
+	}
+
+	return Template{}, false
+}
+
+// BaseFunc returns a stored blueprint that templates of an upload extend.
+type BaseFunc func(kind *blueprint.Kind, slug string) (registry.Request, error)
+
+// Base looks bases up in the blueprints of b, which may be nil.
+func (b *BlueprintBatchRequest) Base() BaseFunc {
+	return func(kind *blueprint.Kind, slug string) (registry.Request, error) {
+		if b != nil {
+			for _, def := range b.Blueprints[kind.Name] {
+				if def.GetSlug() == slug {
+					return def, nil
+				}
+			}
+		}
+
+		return nil, ErrBaseNotFound
+	}
+}
+
+// Resolve merges every template of the batch onto its base and adds the
+// result to the blueprints of the batch. Bases are looked up in the batch
+// first, then with base if it isn't nil.
+func (b *BlueprintBatchRequest) Resolve(base BaseFunc) error {
+	type key struct{ kind, slug string }
+
+	var (
+		defs      = make(map[key]registry.Request)
+		templates = make(map[key]Template)
+		resolved  = make(map[key]registry.Request)
+	)
+
+	for name, items := range b.Blueprints {
+		for _, def := range items {
+			defs[key{name, def.GetSlug()}] = def
+		}
+	}
+
+	for name, items := range b.Templates {
+		for _, template := range items {
+			k := key{name, template.Slug}
+
+			if _, ok := templates[k]; ok {
+				return fmt.Errorf("%s %s: %w: defined twice", name, template.Slug, ErrInvalidTemplate)
+			}
+
+			templates[k] = template
+		}
+	}
+
+	var resolve func(k key, chain []string) (registry.Request, error)
+	resolve = func(k key, chain []string) (registry.Request, error) {
+		if def, ok := resolved[k]; ok {
+			return def, nil
+		}
+
+		kind, err := blueprint.Get(k.kind)
+		if err != nil {
+			return nil, err
+		}
+
+		template, ok := templates[k]
+		if !ok {
+			if def, ok := defs[k]; ok {
+				return def, nil
+			}
+
+			if base == nil {
+				return nil, fmt.Errorf("%s %s: %w", k.kind, k.slug, ErrBaseNotFound)
+			}
+
+			def, err := base(kind, k.slug)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", k.kind, k.slug, err)
+			}
+
+			return def, nil
+		}
+
+		for _, slug := range chain {
+			if slug == k.slug {
+				return nil, fmt.Errorf("%w: %s", ErrInheritanceCycle, strings.Join(append(chain, k.slug), " -> "))
+			}
+		}
+
+		parent, err := resolve(key{k.kind, template.Extends}, append(chain, k.slug))
+		if err != nil {
+			return nil, err
+		}
+
+		def, err := merge(kind, parent, template.Overrides)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", k.kind, k.slug, err)
+		}
+
+		resolved[k] = def
+
+		return def, nil
+	}
+
+	names := make([]string, 0, len(b.Templates))
+	for name := range b.Templates {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, template := range b.Templates[name] {
+			k := key{name, template.Slug}
+
+			if _, ok := defs[k]; ok {
+				continue
+			}
+
+			def, err := resolve(k, nil)
+			if err != nil {
+				return err
+			}
+
+			if b.Blueprints == nil {
+				b.Blueprints = make(map[string][]registry.Request)
+			}
+
+			b.Blueprints[name] = append(b.Blueprints[name], def)
+			defs[k] = def
+		}
+	}
+
+	return nil
+}
+
+// merge applies overrides to base and decodes the result as a blueprint of
+// kind, which has to be complete.
+func merge(kind *blueprint.Kind, base registry.Request, overrides json.RawMessage) (registry.Request, error) {
+	raw, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var target, patch any
+
+	if err := json.Unmarshal(raw, &target); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(overrides, &patch); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTemplate, err)
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	return kind.DecodeBody(merged)
+}
+
+func mergePatch(target, patch any) any {
+	fields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = make(map[string]any)
+	}
+
+	for name, value := range fields {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+
+		object[name] = mergePatch(object[name], value)
+	}
+
+	return object
+}
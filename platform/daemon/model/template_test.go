@@ -0,0 +1,169 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		label string
+		body  string
+		base  BaseFunc
+		slugs map[string]string
+		err   error
+
+		// invalid is set when the resolved definition fails its schema.
+		invalid bool
+	}{
+		{
+			label: "in batch",
+			body: `{"version": "1.0.0", "buildings": [
+				{"name": "House", "slug": "house", "build_time": "10s", "cost": [{"resource": "wood", "amount": 10}]},
+				{"extends": "house", "slug": "big-house", "name": "Big House", "build_time": "20s"}
+			]}`,
+			slugs: map[string]string{"big-house": "Big House", "house": "House"},
+		},
+		{
+			label: "chain",
+			body: `{"version": "1.0.0", "buildings": [
+				{"extends": "big-house", "slug": "huge-house", "name": "Huge House"},
+				{"extends": "house", "slug": "big-house", "name": "Big House", "build_time": "20s"},
+				{"name": "House", "slug": "house", "build_time": "10s", "cost": [{"resource": "wood", "amount": 10}]}
+			]}`,
+			slugs: map[string]string{"big-house": "Big House", "house": "House", "huge-house": "Huge House"},
+		},
+		{
+			label: "from base",
+			body: `{"version": "1.0.0", "buildings": [
+				{"extends": "house", "slug": "big-house", "name": "Big House"}
+			]}`,
+			base: (&BlueprintBatchRequest{Blueprints: map[string][]registry.Request{
+				KindBuilding: {registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s", Cost: registry.ResourceList{{Resource: "wood", Amount: 10}}}},
+			}}).Base(),
+			slugs: map[string]string{"big-house": "Big House"},
+		},
+		{
+			label: "cycle",
+			body: `{"version": "1.0.0", "buildings": [
+				{"extends": "b", "slug": "a", "name": "A"},
+				{"extends": "a", "slug": "b", "name": "B"}
+			]}`,
+			err: ErrInheritanceCycle,
+		},
+		{
+			label: "missing base",
+			body: `{"version": "1.0.0", "buildings": [
+				{"extends": "house", "slug": "big-house", "name": "Big House"}
+			]}`,
+			err: ErrBaseNotFound,
+		},
+		{
+			label: "incomplete result",
+			body: `{"version": "1.0.0", "buildings": [
+				{"name": "House", "slug": "house", "build_time": "10s", "cost": [{"resource": "wood", "amount": 10}]},
+				{"extends": "house", "slug": "big-house", "name": null}
+			]}`,
+			invalid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			var batch BlueprintBatchRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &batch))
+
+			err := batch.Resolve(tt.base)
+			if tt.invalid {
+				var schemaErr blueprint.SchemaError
+				require.ErrorAs(t, err, &schemaErr)
+
+				return
+			}
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			names := make(map[string]string)
+			for _, def := range batch.Blueprints[KindBuilding] {
+				names[def.GetSlug()] = def.GetName()
+			}
+
+			assert.Equal(t, tt.slugs, names)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
+
+func TestResolveMerge(t *testing.T) {
+	body := `{"version": "1.0.0", "buildings": [
+		{"name": "Sawmill", "slug": "sawmill", "build_time": "1m0s", "cost": [{"resource": "wood", "amount": 20}],
+		 "production": [{"cost": [{"resource": "wood", "amount": 2}], "product": [{"resource": "plank", "amount": 1}], "production_time": "5s"}]},
+		{"extends": "sawmill", "slug": "mill", "name": "Mill", "cost": [{"resource": "stone", "amount": 5}], "production": null}
+	]}`
+
+	var batch BlueprintBatchRequest
+	require.NoError(t, json.Unmarshal([]byte(body), &batch))
+	require.NoError(t, batch.Resolve(nil))
+
+	var mill registry.BuildingBlueprintRequest
+	for _, def := range batch.Blueprints[KindBuilding] {
+		if def.GetSlug() == "mill" {
+			mill = def.(registry.BuildingBlueprintRequest)
+		}
+	}
+
+	assert.Equal(t, "Mill", mill.Name)
+	assert.Equal(t, "1m0s", mill.BuildTime)
+	assert.Equal(t, registry.ResourceList{{Resource: "stone", Amount: 5}}, mill.Cost)
+	assert.Empty(t, mill.Production)
+}
+
+func TestTemplateRoundTrip(t *testing.T) {
+	body := `{"version": "1.0.0", "buildings": [
+		{"name": "House", "slug": "house", "build_time": "10s", "cost": [{"resource": "wood", "amount": 10}]},
+		{"extends": "house", "slug": "big-house", "name": "Big House"}
+	]}`
+
+	var batch BlueprintBatchRequest
+	require.NoError(t, json.Unmarshal([]byte(body), &batch))
+	assert.Equal(t, 2, batch.Len())
+
+	require.NoError(t, batch.Resolve(nil))
+	assert.Equal(t, 2, batch.Len())
+
+	requests := batch.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "house", requests[1].Extends)
+	assert.Equal(t, "big-house", requests[1].Slug())
+
+	raw, err := json.Marshal(batch)
+	require.NoError(t, err)
+
+	var compact struct {
+		Buildings []map[string]any `json:"buildings"`
+	}
+
+	require.NoError(t, json.Unmarshal(raw, &compact))
+	require.Len(t, compact.Buildings, 2)
+	assert.Equal(t, map[string]any{"extends": "house", "slug": "big-house", "name": "Big House"}, compact.Buildings[1])
+
+	single, err := json.Marshal(requests[1])
+	require.NoError(t, err)
+
+	var req BlueprintRequest
+	require.NoError(t, json.Unmarshal(single, &req))
+	assert.Equal(t, "house", req.Extends)
+	assert.Nil(t, req.Definition)
+	assert.JSONEq(t, `{"slug": "big-house", "name": "Big House"}`, string(req.Overrides))
+}
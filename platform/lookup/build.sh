@@ -0,0 +1,8 @@
+#!/bin/sh
+# Generates lookup.pb.go. The kit's messages are imported from its module.
+KIT=$(go list -m -f '{{.Dir}}' github.com/GnarloqGames/genesis-avalon-kit)
+
+protoc --go_out=. --go_opt=paths=source_relative \
+    --go_opt=Mcommon.proto=github.com/GnarloqGames/genesis-avalon-kit/proto \
+    --go_opt=Mregistry.proto=github.com/GnarloqGames/genesis-avalon-kit/proto \
+    --proto_path=. --proto_path="$KIT/proto" lookup.proto
@@ -0,0 +1,350 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/snapshot"
+	kit "github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/transport"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// SubjectGet is the prefix of the subjects single blueprints are looked
+	// up on, followed by the kind: registry.get.building.
+	SubjectGet  = "registry.get"
+	SubjectList = "registry.list"
+
+	// Queue is the queue group of the subscriptions, so every request is
+	// answered by a single gateway instance.
+	Queue = "gateway.registry"
+)
+
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrUnknownKind = errors.New("unknown kind")
+)
+
+// Server answers blueprint lookups on the message bus from the blueprints
+// loaded in the cache, or the snapshot while the registry is unreachable.
+type Server struct {
+	subs []*nats.Subscription
+}
+
+// Serve subscribes to the lookup subjects.
+func Serve(bus *transport.Connection) (*Server, error) {
+	listingHookOnce.Do(func() {
+		cluster.OnLoad(prepareListing)
+	})
+
+	s := &Server{subs: make([]*nats.Subscription, 0, 2)}
+
+	handlers := map[string]nats.MsgHandler{
+		SubjectGet + ".*": s.handleGet,
+		SubjectList:       s.handleList,
+	}
+
+	for subject, handler := range handlers {
+		sub, err := bus.QueueSubscribe(subject, Queue, handler)
+		if err != nil {
+			s.Close() //nolint
+
+			return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+		}
+
+		s.subs = append(s.subs, sub)
+	}
+
+	slog.Info("serving blueprint lookups", "subjects", []string{SubjectGet + ".*", SubjectList}, "queue", Queue)
+
+	return s, nil
+}
+
+func (s *Server) Close() error {
+	var errs []error
+
+	for _, sub := range s.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	s.subs = nil
+
+	return errors.Join(errs...)
+}
+
+func (s *Server) handleGet(msg *nats.Msg) {
+	kind := strings.TrimPrefix(msg.Subject, SubjectGet+".")
+
+	var req GetRequest
+	if err := proto.Unmarshal(msg.Data, &req); err != nil {
+		respond(msg, &GetResponse{Header: header(fmt.Errorf("invalid request: %w", err))})
+		return
+	}
+
+	res, err := Get(context.Background(), kind, req.GetSlug())
+	if err != nil {
+		slog.Debug("blueprint lookup failed", "error", err, "kind", kind, "slug", req.GetSlug(), "trace_id", req.GetHeader().GetTraceID())
+	}
+
+	res.Header = header(err)
+	respond(msg, res)
+}
+
+func (s *Server) handleList(msg *nats.Msg) {
+	var req ListRequest
+	if err := proto.Unmarshal(msg.Data, &req); err != nil {
+		respond(msg, &ListResponse{Header: header(fmt.Errorf("invalid request: %w", err))})
+		return
+	}
+
+	res, err := List(context.Background(), req.GetKind())
+	if err != nil {
+		slog.Debug("blueprint listing failed", "error", err, "kind", req.GetKind(), "trace_id", req.GetHeader().GetTraceID())
+		res = &ListResponse{}
+	}
+
+	res.Header = header(err)
+	respond(msg, res)
+}
+
+// Get looks a blueprint of the active version up by kind and slug.
+func Get(ctx context.Context, kind, slug string) (*GetResponse, error) {
+	if snap, stale := snapshot.Stale(); stale {
+		return getFromSnapshot(snap, kind, slug)
+	}
+
+	res := &GetResponse{Version: cluster.Active()}
+
+	registered, err := heldKind(kind)
+	if err != nil {
+		return res, err
+	}
+
+	bp, ok := registered.Cached(ctx, slug)
+	if !ok {
+		return res, ErrNotFound
+	}
+
+	loaded, ok := bp.(blueprint.Blueprint)
+	if !ok {
+		return res, fmt.Errorf("unexpected %s blueprint %T", kind, bp)
+	}
+
+	return res, res.setBlueprint(loaded)
+}
+
+func getFromSnapshot(snap *snapshot.Snapshot, kind, slug string) (*GetResponse, error) {
+	res := &GetResponse{Version: snap.Version}
+
+	if _, err := heldKind(kind); err != nil {
+		return res, err
+	}
+
+	bp, ok := snap.Blueprints.Find(kind, slug)
+	if !ok {
+		return res, ErrNotFound
+	}
+
+	return res, res.setBlueprint(bp)
+}
+
+// heldKind returns the kind registered under name if the cache holds its
+// blueprints.
+func heldKind(name string) (*blueprint.Kind, error) {
+	kind, err := blueprint.Get(name)
+	if err != nil || !kind.Held() || kind.Cached == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, name)
+	}
+
+	return kind, nil
+}
+
+// setBlueprint puts bp in the field of its kind, kinds without one are sent
+// as Other.
+func (x *GetResponse) setBlueprint(bp blueprint.Blueprint) error {
+	switch typed := bp.(type) {
+	case *kit.BuildingBlueprint:
+		x.Blueprint = &GetResponse_Building{Building: typed}
+	case *kit.ResourceBlueprint:
+		x.Blueprint = &GetResponse_Resource{Resource: typed}
+	default:
+		other, err := anypb.New(bp)
+		if err != nil {
+			return err
+		}
+
+		x.Blueprint = &GetResponse_Other{Other: other}
+	}
+
+	return nil
+}
+
+// List returns the blueprints of the active version, only those of kind if it
+// isn't empty.
+func List(ctx context.Context, kind string) (*ListResponse, error) {
+	if kind != "" {
+		if _, err := heldKind(kind); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		prepared listing
+		err      error
+	)
+
+	if snap, stale := snapshot.Stale(); stale {
+		prepared, err = newListing(snap.Version, snap.Blueprints)
+	} else {
+		prepared, err = listings.get(ctx, cluster.Active())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The prepared responses are shared, only their lists are handed out.
+	res := prepared[kind]
+
+	return &ListResponse{
+		Version:   res.GetVersion(),
+		Buildings: res.GetBuildings(),
+		Resources: res.GetResources(),
+		Others:    res.GetOthers(),
+	}, nil
+}
+
+// listing holds the list responses of a version by kind, and of every kind
+// under the empty kind.
+type listing map[string]*ListResponse
+
+func newListing(version string, set blueprint.Set) (listing, error) {
+	l := listing{"": &ListResponse{Version: version}}
+
+	for _, kind := range blueprint.Held() {
+		res := &ListResponse{Version: version}
+
+		for _, res := range []*ListResponse{res, l[""]} {
+			if err := res.addBlueprints(set[kind.Name]); err != nil {
+				return nil, err
+			}
+		}
+
+		l[kind.Name] = res
+	}
+
+	return l, nil
+}
+
+// listingStore holds the listing of the active version. It is rebuilt after
+// every load of the cache, and on first use after startup.
+type listingStore struct {
+	mx      *sync.Mutex
+	version string
+	current listing
+}
+
+var (
+	listings        = &listingStore{mx: &sync.Mutex{}}
+	listingHookOnce = &sync.Once{}
+)
+
+// get returns the listing of the active version, building it if the version
+// changed.
+func (s *listingStore) get(ctx context.Context, version string) (listing, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.current != nil && s.version == version {
+		return s.current, nil
+	}
+
+	return s.build(ctx, version)
+}
+
+func (s *listingStore) build(ctx context.Context, version string) (listing, error) {
+	l, err := newListing(version, blueprint.FromCache(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	s.version = version
+	s.current = l
+
+	return l, nil
+}
+
+// prepareListing lists a freshly loaded version.
+func prepareListing(ctx context.Context, version string) {
+	listings.mx.Lock()
+	defer listings.mx.Unlock()
+
+	if _, err := listings.build(ctx, version); err != nil {
+		slog.Error("failed to list blueprints", "error", err, "version", version)
+	}
+}
+
+// addBlueprints appends bps to the field of their kind, kinds without one go
+// to Others.
+func (x *ListResponse) addBlueprints(bps []blueprint.Blueprint) error {
+	for _, bp := range bps {
+		switch typed := bp.(type) {
+		case *kit.BuildingBlueprint:
+			x.Buildings = append(x.Buildings, typed)
+		case *kit.ResourceBlueprint:
+			x.Resources = append(x.Resources, typed)
+		default:
+			other, err := anypb.New(bp)
+			if err != nil {
+				return err
+			}
+
+			x.Others = append(x.Others, other)
+		}
+	}
+
+	return nil
+}
+
+func header(err error) *kit.ResponseHeader {
+	h := &kit.ResponseHeader{
+		Timestamp: timestamppb.New(time.Now()),
+		Status:    kit.Status_OK,
+	}
+
+	if err != nil {
+		h.Status = kit.Status_ERROR
+		h.Error = err.Error()
+	}
+
+	return h
+}
+
+func respond(msg *nats.Msg, res proto.Message) {
+	if msg.Reply == "" {
+		return
+	}
+
+	raw, err := proto.Marshal(res)
+	if err != nil {
+		slog.Error("failed to encode lookup response", "error", err, "subject", msg.Subject)
+		return
+	}
+
+	if err := msg.Respond(raw); err != nil {
+		slog.Error("failed to respond to lookup", "error", err, "subject", msg.Subject)
+	}
+}
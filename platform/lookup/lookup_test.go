@@ -0,0 +1,232 @@
+package lookup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/snapshot"
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	kit "github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry/cache"
+	"github.com/GnarloqGames/genesis-avalon-kit/transport"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const version = "1.2.0"
+
+func connect(t *testing.T) *transport.Connection {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true})
+	require.NoError(t, err)
+
+	go ns.Start()
+
+	require.True(t, ns.ReadyForConnections(5*time.Second))
+	t.Cleanup(ns.Shutdown)
+
+	cfg := transport.DefaultConfig
+	cfg.URL = ns.ClientURL()
+
+	bus, err := transport.NewConn(cfg)
+	require.NoError(t, err)
+	t.Cleanup(bus.Close)
+
+	return bus
+}
+
+func loadCache(t *testing.T) {
+	t.Helper()
+
+	database.SetKind(database.DriverMock)
+
+	db, err := database.Get()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for _, slug := range []string{"house", "farm"} {
+		require.NoError(t, db.SaveBuildingBlueprint(ctx, &kit.BuildingBlueprint{Name: slug, Slug: slug, Version: version}))
+	}
+
+	require.NoError(t, db.SaveResourceBlueprint(ctx, &kit.ResourceBlueprint{Name: "Wood", Slug: "wood", Version: version}))
+
+	cache.SetVersion(version)
+	require.NoError(t, cache.Load(ctx))
+
+	cluster.SetActive(version)
+	prepareListing(ctx, version)
+	t.Cleanup(func() { cluster.SetActive("") })
+}
+
+func TestServe(t *testing.T) {
+	loadCache(t)
+
+	bus := connect(t)
+
+	s, err := Serve(bus)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() }) //nolint
+
+	require.NoError(t, bus.Flush())
+
+	get := func(kind, slug string) *GetResponse {
+		var res GetResponse
+
+		_, err := transport.Request(bus.Conn, SubjectGet+"."+kind, &GetRequest{Slug: slug}, &res, time.Second)
+		require.NoError(t, err)
+
+		return &res
+	}
+
+	house := get("building", "house")
+	assert.Equal(t, kit.Status_OK, house.GetHeader().GetStatus())
+	assert.Equal(t, version, house.GetVersion())
+	assert.Equal(t, "house", house.GetBuilding().GetSlug())
+
+	wood := get("resource", "wood")
+	assert.Equal(t, kit.Status_OK, wood.GetHeader().GetStatus())
+	assert.Equal(t, "Wood", wood.GetResource().GetName())
+
+	missing := get("building", "castle")
+	assert.Equal(t, kit.Status_ERROR, missing.GetHeader().GetStatus())
+	assert.Equal(t, ErrNotFound.Error(), missing.GetHeader().GetError())
+	assert.Nil(t, missing.GetBlueprint())
+
+	unknown := get("unit", "knight")
+	assert.Equal(t, kit.Status_ERROR, unknown.GetHeader().GetStatus())
+
+	list := func(kind string) *ListResponse {
+		var res ListResponse
+
+		_, err := transport.Request(bus.Conn, SubjectList, &ListRequest{Kind: kind}, &res, time.Second)
+		require.NoError(t, err)
+
+		return &res
+	}
+
+	all := list("")
+	require.Equal(t, kit.Status_OK, all.GetHeader().GetStatus())
+	assert.Equal(t, version, all.GetVersion())
+	require.Len(t, all.GetBuildings(), 2)
+	assert.Equal(t, "farm", all.GetBuildings()[0].GetSlug())
+	assert.Len(t, all.GetResources(), 1)
+
+	resources := list("resource")
+	assert.Empty(t, resources.GetBuildings())
+	assert.Len(t, resources.GetResources(), 1)
+
+	assert.Equal(t, kit.Status_ERROR, list("unit").GetHeader().GetStatus())
+}
+
+func TestStaleSnapshot(t *testing.T) {
+	loadCache(t)
+
+	snapshot.Serve(&snapshot.Snapshot{
+		Version: "1.1.0",
+		Blueprints: blueprint.Set{
+			blueprint.KindBuilding: {&kit.BuildingBlueprint{Name: "Hut", Slug: "hut"}},
+		},
+	}, nil)
+	t.Cleanup(snapshot.Recover)
+
+	ctx := context.Background()
+
+	res, err := Get(ctx, "building", "hut")
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", res.GetVersion())
+	assert.Equal(t, "Hut", res.GetBuilding().GetName())
+
+	_, err = Get(ctx, "building", "house")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	listed, err := List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, listed.GetBuildings(), 1)
+	assert.Empty(t, listed.GetResources())
+}
+
+// banner is a blueprint of a kind the lookup protos have no field for.
+type banner struct {
+	*wrapperspb.StringValue
+}
+
+func (b banner) GetSlug() string { return b.GetValue() }
+
+func TestRegisteredKind(t *testing.T) {
+	flag := banner{wrapperspb.String("flag")}
+
+	blueprint.Register(&blueprint.Kind{
+		Name:   "banner",
+		Decode: blueprint.Decoder[registry.ResourceBlueprintRequest](),
+		Save:   blueprint.Saver(registry.SaveResourceBlueprint),
+		Lookup: blueprint.Lookup(registry.GetResourceBlueprint),
+		Cached: func(_ context.Context, slug string) (any, bool) {
+			return flag, slug == flag.GetSlug()
+		},
+		Loaded: func(context.Context) []blueprint.Blueprint {
+			return []blueprint.Blueprint{flag}
+		},
+		Read: func(context.Context, string) ([]blueprint.Blueprint, error) {
+			return []blueprint.Blueprint{flag}, nil
+		},
+		New: func() blueprint.Blueprint { return banner{&wrapperspb.StringValue{}} },
+		Request: blueprint.Requester(func(b banner) registry.ResourceBlueprintRequest {
+			return registry.ResourceBlueprintRequest{Name: b.GetValue(), Slug: b.GetSlug()}
+		}),
+	})
+
+	loadCache(t)
+
+	ctx := context.Background()
+
+	res, err := Get(ctx, "banner", "flag")
+	require.NoError(t, err)
+
+	var value wrapperspb.StringValue
+	require.NoError(t, res.GetOther().UnmarshalTo(&value))
+	assert.Equal(t, "flag", value.GetValue())
+
+	listed, err := List(ctx, "banner")
+	require.NoError(t, err)
+	assert.Empty(t, listed.GetBuildings())
+	require.Len(t, listed.GetOthers(), 1)
+
+	// Snapshots keep the kind as well.
+	snapshot.Serve(snapshot.FromCache(ctx, version), nil)
+	t.Cleanup(snapshot.Recover)
+
+	res, err = Get(ctx, "banner", "flag")
+	require.NoError(t, err)
+	assert.NotNil(t, res.GetOther())
+}
+
+func TestListPrepared(t *testing.T) {
+	loadCache(t)
+
+	ctx := context.Background()
+
+	db, err := database.Get()
+	require.NoError(t, err)
+	require.NoError(t, db.SaveBuildingBlueprint(ctx, &kit.BuildingBlueprint{Name: "Mill", Slug: "mill", Version: version}))
+	require.NoError(t, cache.Load(ctx))
+
+	// The listing is prepared once per load, not per request.
+	listed, err := List(ctx, "building")
+	require.NoError(t, err)
+	assert.Len(t, listed.GetBuildings(), 2)
+
+	prepareListing(ctx, version)
+
+	listed, err = List(ctx, "building")
+	require.NoError(t, err)
+	assert.Len(t, listed.GetBuildings(), 3)
+	assert.Empty(t, listed.GetResources())
+}
@@ -0,0 +1,213 @@
+package lru
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+// LoadFunc loads the value of a key that isn't cached.
+type LoadFunc[V any] func(ctx context.Context, key string) (V, error)
+
+// Cache keeps the most recently used values up to a fixed capacity. Concurrent
+// misses for the same key share a single load.
+type Cache[V any] struct {
+	name     string
+	capacity int
+	load     LoadFunc[V]
+
+	mx    *sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+	group *singleflight.Group
+
+	meters meters
+}
+
+type entry[V any] struct {
+	key   string
+	value V
+}
+
+func New[V any](name string, capacity int, load LoadFunc[V]) *Cache[V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	c := &Cache[V]{
+		name:     name,
+		capacity: capacity,
+		load:     load,
+		mx:       &sync.Mutex{},
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		group:    &singleflight.Group{},
+	}
+
+	c.meters = newMeters(name, c.Len)
+
+	return c
+}
+
+// Peek returns a cached value without loading it on a miss.
+func (c *Cache[V]) Peek(ctx context.Context, key string) (V, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	c.meters.record(ctx, c.meters.hits)
+
+	return element.Value.(*entry[V]).value, true
+}
+
+// Get returns the cached value of key, loading and caching it on a miss.
+func (c *Cache[V]) Get(ctx context.Context, key string) (V, error) {
+	if value, ok := c.Peek(ctx, key); ok {
+		return value, nil
+	}
+
+	c.meters.record(ctx, c.meters.misses)
+
+	// The load outlives a caller that gives up, the others may still wait
+	// for it.
+	loadCtx := context.WithoutCancel(ctx)
+
+	result, err, _ := c.group.Do(key, func() (any, error) {
+		if value, ok := c.Peek(loadCtx, key); ok {
+			return value, nil
+		}
+
+		start := time.Now()
+
+		value, err := c.load(loadCtx, key)
+		c.meters.recordLoad(loadCtx, time.Since(start), err)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.add(loadCtx, key, value)
+
+		return value, nil
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	return result.(V), nil
+}
+
+// Remove drops key from the cache.
+func (c *Cache[V]) Remove(key string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.order.Remove(element)
+		delete(c.items, key)
+	}
+}
+
+func (c *Cache[V]) Len() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache[V]) add(ctx context.Context, key string, value V) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*entry[V]).value = value
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry[V]).key)
+
+		c.meters.record(ctx, c.meters.evictions)
+	}
+}
+
+type meters struct {
+	attributes metric.MeasurementOption
+
+	hits      metric.Int64Counter
+	misses    metric.Int64Counter
+	evictions metric.Int64Counter
+	loads     metric.Int64Counter
+	loadTime  metric.Float64Histogram
+}
+
+func newMeters(name string, size func() int) meters {
+	meter := otel.Meter("gateway")
+
+	m := meters{
+		attributes: metric.WithAttributes(attribute.String("cache", name)),
+	}
+
+	// The cache works without metrics, instruments that fail to register
+	// are left nil.
+	m.hits, _ = meter.Int64Counter("cache.hits",
+		metric.WithDescription("Number of cache hits"),
+		metric.WithUnit("{hit}"))
+	m.misses, _ = meter.Int64Counter("cache.misses",
+		metric.WithDescription("Number of cache misses"),
+		metric.WithUnit("{miss}"))
+	m.evictions, _ = meter.Int64Counter("cache.evictions",
+		metric.WithDescription("Number of entries evicted from the cache"),
+		metric.WithUnit("{entry}"))
+	m.loads, _ = meter.Int64Counter("cache.loads",
+		metric.WithDescription("Number of loads after collapsing concurrent misses"),
+		metric.WithUnit("{load}"))
+	m.loadTime, _ = meter.Float64Histogram("cache.load.length",
+		metric.WithDescription("Time spent loading missing entries"),
+		metric.WithUnit("ms"))
+
+	_, _ = meter.Int64ObservableGauge("cache.size",
+		metric.WithDescription("Number of entries in the cache"),
+		metric.WithUnit("{entry}"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(size()), metric.WithAttributes(attribute.String("cache", name)))
+			return nil
+		}))
+
+	return m
+}
+
+func (m meters) record(ctx context.Context, counter metric.Int64Counter) {
+	if counter != nil {
+		counter.Add(ctx, 1, m.attributes)
+	}
+}
+
+func (m meters) recordLoad(ctx context.Context, measure time.Duration, err error) {
+	if m.loads != nil {
+		m.loads.Add(ctx, 1, m.attributes, metric.WithAttributes(attribute.Bool("error", err != nil)))
+	}
+
+	if m.loadTime != nil {
+		m.loadTime.Record(ctx, float64(measure.Milliseconds()), m.attributes)
+	}
+}
@@ -0,0 +1,95 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEviction(t *testing.T) {
+	var loads atomic.Int32
+
+	c := New("test", 2, func(ctx context.Context, key string) (string, error) {
+		loads.Add(1)
+		return "value-" + key, nil
+	})
+
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "a", "c"} {
+		value, err := c.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, "value-"+key, value)
+	}
+
+	assert.Equal(t, int32(3), loads.Load())
+	assert.Equal(t, 2, c.Len())
+
+	_, ok := c.Peek(ctx, "b")
+	assert.False(t, ok, "least recently used key is evicted")
+
+	_, ok = c.Peek(ctx, "a")
+	assert.True(t, ok)
+
+	c.Remove("a")
+	_, ok = c.Peek(ctx, "a")
+	assert.False(t, ok)
+}
+
+func TestSingleflight(t *testing.T) {
+	var (
+		loads   atomic.Int32
+		release = make(chan struct{})
+	)
+
+	c := New("test", 4, func(ctx context.Context, key string) (int, error) {
+		loads.Add(1)
+		<-release
+
+		return 42, nil
+	})
+
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			value, err := c.Get(context.Background(), "key")
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestLoadError(t *testing.T) {
+	var loads atomic.Int32
+
+	c := New("test", 4, func(ctx context.Context, key string) (int, error) {
+		loads.Add(1)
+		return 0, errors.New("boom")
+	})
+
+	_, err := c.Get(context.Background(), "key")
+	assert.Error(t, err)
+
+	_, err = c.Get(context.Background(), "key")
+	assert.Error(t, err)
+
+	assert.Equal(t, int32(2), loads.Load(), "errors aren't cached")
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,284 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cockroach"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS blueprint_versions (
+		version STRING PRIMARY KEY,
+		state STRING NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		promoted_by STRING NOT NULL DEFAULT ''
+	)`,
+	`CREATE TABLE IF NOT EXISTS blueprint_channels (
+		channel STRING PRIMARY KEY,
+		version STRING NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS blueprint_annotations (
+		version STRING NOT NULL,
+		kind STRING NOT NULL,
+		slug STRING NOT NULL,
+		deprecated BOOL NOT NULL DEFAULT false,
+		deprecated_at TIMESTAMPTZ NULL,
+		deprecated_by STRING NOT NULL DEFAULT '',
+		PRIMARY KEY (version, kind, slug)
+	)`,
+	`ALTER TABLE blueprint_annotations ADD COLUMN IF NOT EXISTS extends STRING NOT NULL DEFAULT ''`,
+	`ALTER TABLE blueprint_annotations ADD COLUMN IF NOT EXISTS overrides JSONB NULL`,
+	`ALTER TABLE blueprint_annotations ADD COLUMN IF NOT EXISTS locales JSONB NULL`,
+}
+
+var blueprintColumns = []string{"version", "kind", "slug", "deprecated", "deprecated_at", "deprecated_by", "extends", "overrides", "locales"}
+
+type CockroachStore struct {
+	pool *pgxpool.Pool
+	psql sq.StatementBuilderType
+}
+
+func NewCockroachStore(ctx context.Context) (*CockroachStore, error) {
+	pool, err := cockroach.Pool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range schema {
+		if _, err := pool.Exec(ctx, statement); err != nil {
+			return nil, fmt.Errorf("schema: %w", err)
+		}
+	}
+
+	return &CockroachStore{
+		pool: pool,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}, nil
+}
+
+func (s *CockroachStore) GetVersion(ctx context.Context, version string) (*Version, error) {
+	query, params, err := s.psql.Select("version", "state", "created_at", "updated_at", "promoted_by").
+		From("blueprint_versions").
+		Where("version = ?", version).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var v Version
+
+	row := s.pool.QueryRow(ctx, query, params...)
+	if err := row.Scan(&v.Version, &v.State, &v.CreatedAt, &v.UpdatedAt, &v.PromotedBy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	return &v, nil
+}
+
+func (s *CockroachStore) SaveVersion(ctx context.Context, version *Version) error {
+	query, params, err := s.psql.Insert("blueprint_versions").
+		Columns("version", "state", "created_at", "updated_at", "promoted_by").
+		Values(version.Version, version.State, version.CreatedAt, version.UpdatedAt, version.PromotedBy).
+		Suffix("ON CONFLICT (version) DO UPDATE SET state = excluded.state, updated_at = excluded.updated_at, promoted_by = excluded.promoted_by").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, query, params...)
+
+	return err
+}
+
+func (s *CockroachStore) ListVersions(ctx context.Context) ([]*Version, error) {
+	query, params, err := s.psql.Select("version", "state", "created_at", "updated_at", "promoted_by").
+		From("blueprint_versions").
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]*Version, 0)
+
+	for rows.Next() {
+		var v Version
+		if err := rows.Scan(&v.Version, &v.State, &v.CreatedAt, &v.UpdatedAt, &v.PromotedBy); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		versions = append(versions, &v)
+	}
+
+	return versions, rows.Err()
+}
+
+func (s *CockroachStore) GetChannel(ctx context.Context, channel State) (string, error) {
+	query, params, err := s.psql.Select("version").
+		From("blueprint_channels").
+		Where("channel = ?", channel).
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	var version string
+	if err := s.pool.QueryRow(ctx, query, params...).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+
+		return "", fmt.Errorf("scan: %w", err)
+	}
+
+	return version, nil
+}
+
+func (s *CockroachStore) SetChannel(ctx context.Context, channel State, version string) error {
+	query, params, err := s.psql.Insert("blueprint_channels").
+		Columns("channel", "version").
+		Values(channel, version).
+		Suffix("ON CONFLICT (channel) DO UPDATE SET version = excluded.version").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, query, params...)
+
+	return err
+}
+
+func (s *CockroachStore) GetBlueprint(ctx context.Context, version, kind, slug string) (*Blueprint, error) {
+	query, params, err := s.psql.Select(blueprintColumns...).
+		From("blueprint_annotations").
+		Where("version = ? AND kind = ? AND slug = ?", version, kind, slug).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := scanBlueprint(s.pool.QueryRow(ctx, query, params...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	return b, nil
+}
+
+func (s *CockroachStore) SaveBlueprint(ctx context.Context, blueprint *Blueprint) error {
+	var overrides, locales any
+	if len(blueprint.Overrides) > 0 {
+		overrides = string(blueprint.Overrides)
+	}
+
+	if len(blueprint.Locales) > 0 {
+		raw, err := json.Marshal(blueprint.Locales)
+		if err != nil {
+			return err
+		}
+
+		locales = string(raw)
+	}
+
+	query, params, err := s.psql.Insert("blueprint_annotations").
+		Columns(blueprintColumns...).
+		Values(blueprint.Version, blueprint.Kind, blueprint.Slug, blueprint.Deprecated, blueprint.DeprecatedAt, blueprint.DeprecatedBy, blueprint.Extends, overrides, locales).
+		Suffix("ON CONFLICT (version, kind, slug) DO UPDATE SET deprecated = excluded.deprecated, deprecated_at = excluded.deprecated_at, deprecated_by = excluded.deprecated_by, extends = excluded.extends, overrides = excluded.overrides, locales = excluded.locales").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, query, params...)
+
+	return err
+}
+
+func (s *CockroachStore) ListBlueprints(ctx context.Context, version string) ([]*Blueprint, error) {
+	query, params, err := s.psql.Select(blueprintColumns...).
+		From("blueprint_annotations").
+		Where("version = ?", version).
+		OrderBy("kind", "slug").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	blueprints := make([]*Blueprint, 0)
+
+	for rows.Next() {
+		b, err := scanBlueprint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		blueprints = append(blueprints, b)
+	}
+
+	return blueprints, rows.Err()
+}
+
+func (s *CockroachStore) DeleteBlueprint(ctx context.Context, version, kind, slug string) error {
+	query, params, err := s.psql.Delete("blueprint_annotations").
+		Where("version = ? AND kind = ? AND slug = ?", version, kind, slug).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, query, params...)
+
+	return err
+}
+
+func scanBlueprint(row pgx.Row) (*Blueprint, error) {
+	var (
+		b         Blueprint
+		overrides []byte
+		locales   []byte
+	)
+
+	if err := row.Scan(&b.Version, &b.Kind, &b.Slug, &b.Deprecated, &b.DeprecatedAt, &b.DeprecatedBy, &b.Extends, &overrides, &locales); err != nil {
+		return nil, err
+	}
+
+	if len(overrides) > 0 {
+		b.Overrides = overrides
+	}
+
+	if len(locales) > 0 {
+		if err := json.Unmarshal(locales, &b.Locales); err != nil {
+			return nil, fmt.Errorf("locales: %w", err)
+		}
+	}
+
+	return &b, nil
+}
@@ -0,0 +1,137 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps metadata in memory, for tests and local development.
+type MemoryStore struct {
+	mx         *sync.RWMutex
+	versions   map[string]Version
+	channels   map[State]string
+	blueprints map[string]Blueprint
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mx:         &sync.RWMutex{},
+		versions:   make(map[string]Version),
+		channels:   make(map[State]string),
+		blueprints: make(map[string]Blueprint),
+	}
+}
+
+func (s *MemoryStore) GetVersion(ctx context.Context, version string) (*Version, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	v, ok := s.versions[version]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &v, nil
+}
+
+func (s *MemoryStore) SaveVersion(ctx context.Context, version *Version) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.versions[version.Version] = *version
+
+	return nil
+}
+
+func (s *MemoryStore) ListVersions(ctx context.Context) ([]*Version, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	versions := make([]*Version, 0, len(s.versions))
+
+	for key := range s.versions {
+		v := s.versions[key]
+		versions = append(versions, &v)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].CreatedAt.Before(versions[j].CreatedAt) })
+
+	return versions, nil
+}
+
+func (s *MemoryStore) GetChannel(ctx context.Context, channel State) (string, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	version, ok := s.channels[channel]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return version, nil
+}
+
+func (s *MemoryStore) SetChannel(ctx context.Context, channel State, version string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.channels[channel] = version
+
+	return nil
+}
+
+func (s *MemoryStore) GetBlueprint(ctx context.Context, version, kind, slug string) (*Blueprint, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	b, ok := s.blueprints[blueprintKey(version, kind, slug)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &b, nil
+}
+
+func (s *MemoryStore) SaveBlueprint(ctx context.Context, blueprint *Blueprint) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.blueprints[blueprintKey(blueprint.Version, blueprint.Kind, blueprint.Slug)] = *blueprint
+
+	return nil
+}
+
+func (s *MemoryStore) ListBlueprints(ctx context.Context, version string) ([]*Blueprint, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	blueprints := make([]*Blueprint, 0)
+
+	for key := range s.blueprints {
+		b := s.blueprints[key]
+		if b.Version == version {
+			blueprints = append(blueprints, &b)
+		}
+	}
+
+	sort.Slice(blueprints, func(i, j int) bool {
+		return blueprintKey("", blueprints[i].Kind, blueprints[i].Slug) < blueprintKey("", blueprints[j].Kind, blueprints[j].Slug)
+	})
+
+	return blueprints, nil
+}
+
+func (s *MemoryStore) DeleteBlueprint(ctx context.Context, version, kind, slug string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	delete(s.blueprints, blueprintKey(version, kind, slug))
+
+	return nil
+}
+
+func blueprintKey(version, kind, slug string) string {
+	return fmt.Sprintf("%s/%s/%s", version, kind, slug)
+}
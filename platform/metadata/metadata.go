@@ -0,0 +1,259 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+)
+
+type State string
+
+const (
+	StateDraft   State = "draft"
+	StateStaging State = "staging"
+	StateLive    State = "live"
+)
+
+var (
+	ErrNotFound          = fmt.Errorf("not found")
+	ErrFrozen            = fmt.Errorf("version is frozen")
+	ErrInvalidState      = fmt.Errorf("invalid state")
+	ErrInvalidTransition = fmt.Errorf("invalid state transition")
+)
+
+// Version is the lifecycle record the gateway keeps for a blueprint version.
+type Version struct {
+	Version    string    `json:"version"`
+	State      State     `json:"state"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	PromotedBy string    `json:"promoted_by,omitempty"`
+}
+
+func NewVersion(version string) *Version {
+	now := time.Now().UTC()
+
+	return &Version{
+		Version:   version,
+		State:     StateDraft,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Frozen reports whether blueprints can no longer be written to the version.
+func (v *Version) Frozen() bool {
+	return v.State != StateDraft
+}
+
+// Promote moves the version to the target state. Drafts go to staging,
+// staging versions go live, and a version already in the target state can be
+// promoted again to point the channel back at it.
+func (v *Version) Promote(target State, subject string) error {
+	switch target {
+	case StateStaging:
+		if v.State != StateDraft && v.State != StateStaging {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, v.State, target)
+		}
+	case StateLive:
+		if v.State != StateStaging && v.State != StateLive {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, v.State, target)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidState, target)
+	}
+
+	v.State = target
+	v.PromotedBy = subject
+	v.UpdatedAt = time.Now().UTC()
+
+	return nil
+}
+
+func ParseState(value string) (State, error) {
+	switch State(value) {
+	case StateDraft, StateStaging, StateLive:
+		return State(value), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidState, value)
+	}
+}
+
+// ChannelForEnvironment returns the state whose channel gateways in the given
+// environment follow, or an empty state if they use a fixed version.
+func ChannelForEnvironment(environment string) State {
+	switch environment {
+	case "staging":
+		return StateStaging
+	case "production":
+		return StateLive
+	default:
+		return ""
+	}
+}
+
+// Blueprint holds the gateway's annotations for a single blueprint. The
+// definition itself lives in the kit's registry tables.
+type Blueprint struct {
+	Version      string     `json:"version"`
+	Kind         string     `json:"kind"`
+	Slug         string     `json:"slug"`
+	Deprecated   bool       `json:"deprecated"`
+	DeprecatedAt *time.Time `json:"deprecated_at,omitempty"`
+	DeprecatedBy string     `json:"deprecated_by,omitempty"`
+
+	// Extends names the blueprint this one was created from, with Overrides
+	// merged onto it. The registry holds the resolved definition.
+	Extends   string          `json:"extends,omitempty"`
+	Overrides json.RawMessage `json:"overrides,omitempty"`
+
+	Locales Locales `json:"locales,omitempty"`
+}
+
+// Display is the text players see for a blueprint in one locale.
+type Display struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Locales holds the display text of a blueprint keyed by BCP 47 language tag.
+type Locales map[string]Display
+
+func NewBlueprint(version, kind, slug string) *Blueprint {
+	return &Blueprint{
+		Version: version,
+		Kind:    kind,
+		Slug:    slug,
+	}
+}
+
+func (b *Blueprint) Deprecate(subject string) {
+	now := time.Now().UTC()
+
+	b.Deprecated = true
+	b.DeprecatedAt = &now
+	b.DeprecatedBy = subject
+}
+
+// SetTemplate records the template the blueprint was resolved from. An empty
+// extends clears it.
+func (b *Blueprint) SetTemplate(extends string, overrides json.RawMessage) {
+	if extends == "" {
+		overrides = nil
+	}
+
+	b.Extends = extends
+	b.Overrides = overrides
+}
+
+func (b *Blueprint) Undeprecate() {
+	b.Deprecated = false
+	b.DeprecatedAt = nil
+	b.DeprecatedBy = ""
+}
+
+type Store interface {
+	GetVersion(ctx context.Context, version string) (*Version, error)
+	SaveVersion(ctx context.Context, version *Version) error
+	ListVersions(ctx context.Context) ([]*Version, error)
+
+	GetChannel(ctx context.Context, channel State) (string, error)
+	SetChannel(ctx context.Context, channel State, version string) error
+
+	GetBlueprint(ctx context.Context, version, kind, slug string) (*Blueprint, error)
+	SaveBlueprint(ctx context.Context, blueprint *Blueprint) error
+	ListBlueprints(ctx context.Context, version string) ([]*Blueprint, error)
+
+	// DeleteBlueprint removes the annotations of a blueprint, if it has any.
+	DeleteBlueprint(ctx context.Context, version, kind, slug string) error
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*CockroachStore)(nil)
+)
+
+var (
+	kind  = database.DriverCockroach
+	store Store
+	mx    = &sync.Mutex{}
+)
+
+// SetKind selects the backend with the same driver names as the kit's
+// database package. The store opened before is dropped, a memory store starts
+// out empty again.
+func SetKind(driver string) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	switch driver {
+	case database.DriverCockroach, database.DriverMock:
+		store = nil
+		kind = driver
+	}
+}
+
+func Get() (Store, error) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	if store != nil {
+		return store, nil
+	}
+
+	switch kind {
+	case database.DriverMock:
+		store = NewMemoryStore()
+	case database.DriverCockroach:
+		s, err := NewCockroachStore(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		store = s
+	default:
+		return nil, fmt.Errorf("invalid metadata driver")
+	}
+
+	return store, nil
+}
+
+// GetOrNewBlueprint returns the annotations for a blueprint, or an empty record
+// if there are none yet.
+func GetOrNewBlueprint(ctx context.Context, s Store, version, kind, slug string) (*Blueprint, error) {
+	b, err := s.GetBlueprint(ctx, version, kind, slug)
+	if err == nil {
+		return b, nil
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	return NewBlueprint(version, kind, slug), nil
+}
+
+// EnsureVersion returns the record for a version, creating a draft if the
+// version hasn't been seen before.
+func EnsureVersion(ctx context.Context, s Store, version string) (*Version, error) {
+	v, err := s.GetVersion(ctx, version)
+	if err == nil {
+		return v, nil
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	v = NewVersion(version)
+	if err := s.SaveVersion(ctx, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,67 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromote(t *testing.T) {
+	tests := []struct {
+		label       string
+		from        State
+		to          State
+		expectError bool
+	}{
+		{label: "draft-staging", from: StateDraft, to: StateStaging},
+		{label: "staging-live", from: StateStaging, to: StateLive},
+		{label: "live-live", from: StateLive, to: StateLive},
+		{label: "draft-live", from: StateDraft, to: StateLive, expectError: true},
+		{label: "live-staging", from: StateLive, to: StateStaging, expectError: true},
+		{label: "staging-draft", from: StateStaging, to: StateDraft, expectError: true},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			v := NewVersion("1.0.0")
+			v.State = tt.from
+
+			err := v.Promote(tt.to, "tester")
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Equal(t, tt.from, v.State)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, v.State)
+			assert.Equal(t, "tester", v.PromotedBy)
+			assert.True(t, v.Frozen())
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
+
+func TestEnsureVersion(t *testing.T) {
+	store := NewMemoryStore()
+
+	v, err := EnsureVersion(context.Background(), store, "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, StateDraft, v.State)
+	assert.False(t, v.Frozen())
+
+	require.NoError(t, v.Promote(StateStaging, "tester"))
+	require.NoError(t, store.SaveVersion(context.Background(), v))
+
+	v, err = EnsureVersion(context.Background(), store, "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, StateStaging, v.State)
+
+	versions, err := store.ListVersions(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+}
@@ -0,0 +1,112 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var ErrInvalidVersion = errors.New("invalid version")
+
+// ParseVersion parses a blueprint version. Versions are full semantic
+// versions, like 1.4.0 or 2.0.0-rc.1, without a v prefix.
+func ParseVersion(version string) (*semver.Version, error) {
+	v, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %s", ErrInvalidVersion, version, err)
+	}
+
+	return v, nil
+}
+
+// CompareVersions orders versions by semantic version precedence. Versions
+// from before they had to be semantic versions sort first, by name.
+func CompareVersions(a, b string) int {
+	va, errA := semver.StrictNewVersion(a)
+	vb, errB := semver.StrictNewVersion(b)
+
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SortVersions sorts versions from the lowest to the highest.
+func SortVersions(versions []*Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return CompareVersions(versions[i].Version, versions[j].Version) < 0
+	})
+}
+
+// LatestVersion returns the highest of versions that satisfies every
+// constraint. Without constraints it is the highest version that isn't a
+// prerelease. Drafts only match if drafts is set, versions that aren't
+// semantic versions never match.
+func LatestVersion(versions []*Version, drafts bool, constraints ...*semver.Constraints) (string, bool) {
+	var latest *semver.Version
+
+	for _, version := range versions {
+		if !drafts && !version.Frozen() {
+			continue
+		}
+
+		v, err := semver.StrictNewVersion(version.Version)
+		if err != nil {
+			continue
+		}
+
+		if len(constraints) == 0 && v.Prerelease() != "" {
+			continue
+		}
+
+		if !satisfies(v, constraints) {
+			continue
+		}
+
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+
+	if latest == nil {
+		return "", false
+	}
+
+	return latest.Original(), true
+}
+
+func satisfies(v *semver.Version, constraints []*semver.Constraints) bool {
+	for _, constraint := range constraints {
+		if !constraint.Check(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FilterVersions returns the semantic versions that satisfy constraint.
+func FilterVersions(versions []*Version, constraint *semver.Constraints) []*Version {
+	filtered := make([]*Version, 0, len(versions))
+
+	for _, version := range versions {
+		v, err := semver.StrictNewVersion(version.Version)
+		if err == nil && constraint.Check(v) {
+			filtered = append(filtered, version)
+		}
+	}
+
+	return filtered
+}
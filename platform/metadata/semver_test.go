@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	for _, version := range []string{"1.0.0", "1.4.2", "2.0.0-rc.1", "1.0.0+build.5"} {
+		_, err := ParseVersion(version)
+		assert.NoError(t, err, version)
+	}
+
+	for _, version := range []string{"", "dev", "1", "1.4", "v1.0.0", "1.0.0.0"} {
+		_, err := ParseVersion(version)
+		assert.ErrorIs(t, err, ErrInvalidVersion, version)
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	versions := []*Version{
+		NewVersion("1.10.0"),
+		NewVersion("1.2.0"),
+		NewVersion("dev"),
+		NewVersion("2.0.0-rc.1"),
+		NewVersion("2.0.0"),
+		NewVersion("1.2.0-beta"),
+	}
+
+	SortVersions(versions)
+
+	names := make([]string, 0, len(versions))
+	for _, v := range versions {
+		names = append(names, v.Version)
+	}
+
+	assert.Equal(t, []string{"dev", "1.2.0-beta", "1.2.0", "1.10.0", "2.0.0-rc.1", "2.0.0"}, names)
+}
+
+func TestLatestVersion(t *testing.T) {
+	versions := []*Version{
+		NewVersion("1.3.9"),
+		NewVersion("1.4.0"),
+		NewVersion("1.4.3"),
+		NewVersion("2.0.0"),
+		NewVersion("2.1.0-rc.1"),
+		NewVersion("dev"),
+	}
+
+	for _, v := range versions {
+		v.State = StateLive
+	}
+
+	versions = append(versions, NewVersion("1.4.4"), NewVersion("2.2.0"))
+
+	tests := []struct {
+		label       string
+		constraints []string
+		drafts      bool
+		expected    string
+		found       bool
+	}{
+		{label: "latest", expected: "2.0.0", found: true},
+		{label: "minor", constraints: []string{"1.4"}, expected: "1.4.3", found: true},
+		{label: "major", constraints: []string{"1"}, expected: "1.4.3", found: true},
+		{label: "range", constraints: []string{">=1.3 <1.4"}, expected: "1.3.9", found: true},
+		{label: "combined", constraints: []string{"1", "<1.4.2"}, expected: "1.4.0", found: true},
+		{label: "prerelease", constraints: []string{">=2.1.0-rc.0"}, expected: "2.1.0-rc.1", found: true},
+		{label: "none", constraints: []string{"3"}},
+		{label: "drafts", drafts: true, expected: "2.2.0", found: true},
+		{label: "minor drafts", constraints: []string{"1.4"}, drafts: true, expected: "1.4.4", found: true},
+	}
+
+	for _, tt := range tests {
+		tf := func(t *testing.T) {
+			constraints := make([]*semver.Constraints, 0, len(tt.constraints))
+			for _, raw := range tt.constraints {
+				c, err := semver.NewConstraint(raw)
+				require.NoError(t, err)
+
+				constraints = append(constraints, c)
+			}
+
+			latest, ok := LatestVersion(versions, tt.drafts, constraints...)
+			assert.Equal(t, tt.found, ok)
+			assert.Equal(t, tt.expected, latest)
+		}
+
+		t.Run(tt.label, tf)
+	}
+}
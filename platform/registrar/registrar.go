@@ -0,0 +1,265 @@
+// Package registrar writes blueprint requests to the registry the same way for
+// every client: uploads, imports and the blueprint directory watcher.
+package registrar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+)
+
+var (
+	ErrMissingKind       = errors.New("missing kind field")
+	ErrInvalidKind       = errors.New("invalid kind field")
+	ErrMissingDefinition = errors.New("missing body field")
+)
+
+// SaveBlueprints writes resolved blueprint requests: their versions have to be
+// writable, the definitions valid, and every save is recorded in the audit
+// trail as made by subject.
+func SaveBlueprints(ctx context.Context, subject string, requests []model.BlueprintRequest) error {
+	writable := make(map[string]error)
+	trail := NewTrail(subject)
+
+	for i := range requests {
+		req := &requests[i]
+
+		checked, ok := writable[req.Version]
+		if !ok {
+			checked = CheckWritable(ctx, req.Version)
+			writable[req.Version] = checked
+		}
+
+		if checked != nil {
+			return fmt.Errorf("version %s: %w", req.Version, checked)
+		}
+
+		if err := Validate(req); err != nil {
+			return fmt.Errorf("%s/%s: %w", req.Kind, req.Slug(), err)
+		}
+
+		if err := SaveBlueprint(ctx, req, trail); err != nil {
+			return fmt.Errorf("%s/%s: %w", req.Kind, req.Slug(), err)
+		}
+	}
+
+	return nil
+}
+
+// SaveBlueprint writes the definition to the registry and records the template
+// it was resolved from, so exports can reproduce it, and its display text. The
+// save is added to trail.
+func SaveBlueprint(ctx context.Context, req *model.BlueprintRequest, trail *Trail) error {
+	kind, err := blueprint.Get(req.Kind)
+	if err != nil {
+		return ErrInvalidKind
+	}
+
+	before, err := trail.previous(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	// The save is recorded before it's written, so nothing is ever written
+	// without a trace.
+	record, err := trail.save(ctx, req, before)
+	if err != nil {
+		return err
+	}
+
+	// Forced saves replace the stored definition and keep its annotations,
+	// new blueprints are just added.
+	err = kind.SaveDefinition(ctx, req.Version, req.Definition, before != nil && req.Force)
+	if err == nil {
+		err = saveAnnotation(ctx, req)
+	}
+
+	if err != nil {
+		return errors.Join(err, trail.fail(ctx, record))
+	}
+
+	return nil
+}
+
+func saveAnnotation(ctx context.Context, req *model.BlueprintRequest) error {
+	store, err := metadata.Get()
+	if err != nil {
+		return err
+	}
+
+	annotation, err := metadata.GetOrNewBlueprint(ctx, store, req.Version, req.Kind, req.Definition.GetSlug())
+	if err != nil {
+		return err
+	}
+
+	if req.Extends == "" && annotation.Extends == "" && len(req.Locales) == 0 && len(annotation.Locales) == 0 {
+		return nil
+	}
+
+	annotation.SetTemplate(req.Extends, req.Overrides)
+	annotation.Locales = req.Locales
+
+	return store.SaveBlueprint(ctx, annotation)
+}
+
+func validateKind(req *model.BlueprintRequest) error {
+	if req.Kind == "" {
+		return ErrMissingKind
+	}
+
+	if _, err := blueprint.Get(req.Kind); err != nil {
+		return ErrInvalidKind
+	}
+
+	return nil
+}
+
+// Validate checks the kind of req and validates its definition with the
+// schema and validator registered for that kind.
+func Validate(req *model.BlueprintRequest) error {
+	if err := validateKind(req); err != nil {
+		return err
+	}
+
+	if req.Definition == nil {
+		return ErrMissingDefinition
+	}
+
+	kind, err := blueprint.Get(req.Kind)
+	if err != nil {
+		return ErrInvalidKind
+	}
+
+	return kind.ValidateDefinition(req.Definition)
+}
+
+// Resolve merges a request extending another blueprint onto its base in the
+// registry. Other requests are left as they are.
+func Resolve(ctx context.Context, req *model.BlueprintRequest) error {
+	if req.Extends == "" {
+		return nil
+	}
+
+	if err := validateKind(req); err != nil {
+		return err
+	}
+
+	batch := &model.BlueprintBatchRequest{Version: req.Version}
+	if err := batch.Add(*req); err != nil {
+		return err
+	}
+
+	if err := batch.Resolve(Base(ctx, req.Version)); err != nil {
+		return err
+	}
+
+	req.Definition = batch.Blueprints[req.Kind][0]
+
+	return nil
+}
+
+// Base looks the bases of templates up in version, listing each kind once.
+func Base(ctx context.Context, version string) model.BaseFunc {
+	listed := make(map[string][]registry.Request)
+
+	return func(kind *blueprint.Kind, slug string) (registry.Request, error) {
+		defs, ok := listed[kind.Name]
+		if !ok {
+			if kind.List == nil {
+				return nil, model.ErrBaseNotFound
+			}
+
+			var err error
+
+			defs, err = kind.List(ctx, version)
+			if err != nil {
+				return nil, err
+			}
+
+			listed[kind.Name] = defs
+		}
+
+		for _, def := range defs {
+			if def.GetSlug() == slug {
+				return def, nil
+			}
+		}
+
+		return nil, model.ErrBaseNotFound
+	}
+}
+
+// CheckWritable records the version as a draft on its first write and refuses
+// writes to versions that have been promoted.
+func CheckWritable(ctx context.Context, version string) error {
+	store, err := metadata.Get()
+	if err != nil {
+		return err
+	}
+
+	v, err := store.GetVersion(ctx, version)
+	if errors.Is(err, metadata.ErrNotFound) {
+		v, err = registerVersion(ctx, store, version)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if v.Frozen() {
+		return metadata.ErrFrozen
+	}
+
+	return nil
+}
+
+// CheckFrozen refuses changes to versions that have been promoted, without
+// recording anything. Versions without a record are drafts, like
+// CheckWritable records them.
+func CheckFrozen(ctx context.Context, version string) error {
+	store, err := metadata.Get()
+	if err != nil {
+		return err
+	}
+
+	v, err := store.GetVersion(ctx, version)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if v.Frozen() {
+		return metadata.ErrFrozen
+	}
+
+	return nil
+}
+
+// registerVersion records a version there is no lifecycle record of yet as a
+// draft, it takes an explicit promotion to freeze it. New versions have to be
+// semantic versions, versions that already have blueprints in the registry
+// predate the records and keep their names.
+func registerVersion(ctx context.Context, store metadata.Store, version string) (*metadata.Version, error) {
+	if _, err := metadata.ParseVersion(version); err != nil {
+		// The registry is read directly, the version may be the active one
+		// and the cache not loaded yet.
+		set, readErr := blueprint.ReadSet(ctx, version)
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		if set.Len() == 0 {
+			return nil, err
+		}
+	}
+
+	return metadata.EnsureVersion(ctx, store, version)
+}
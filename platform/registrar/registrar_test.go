@@ -0,0 +1,166 @@
+package registrar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint/mockwriter"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	"github.com/GnarloqGames/genesis-avalon-kit/database/mock"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupStores selects the mock database, with a writer to delete and replace
+// its blueprints, and fresh memory stores for the metadata and the audit
+// trail, so nothing written by earlier tests is left.
+func setupStores(t *testing.T) {
+	t.Helper()
+
+	database.SetKind(database.DriverMock)
+	metadata.SetKind(database.DriverMock)
+	audit.SetKind(database.DriverMock)
+	blueprint.SetWriter(mockwriter.New())
+
+	db, err := mock.Get()
+	require.NoError(t, err)
+
+	db.Buildings = make([]*proto.Building, 0)
+	db.BuildingBlueprints = make([]*proto.BuildingBlueprint, 0)
+	db.ResourceBlueprints = make([]*proto.ResourceBlueprint, 0)
+}
+
+func TestSaveBlueprints(t *testing.T) {
+	setupStores(t)
+
+	const version = "1.0.0"
+
+	ctx := context.Background()
+
+	requests := []model.BlueprintRequest{
+		{Kind: model.KindBuilding, Version: version, Definition: registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"}},
+		{Kind: model.KindResource, Version: version, Definition: registry.ResourceBlueprintRequest{Name: "Wood", Slug: "wood"}},
+	}
+
+	require.NoError(t, SaveBlueprints(ctx, "tester", requests))
+
+	bp, err := registry.GetBuildingBlueprint(ctx, version, "house")
+	require.NoError(t, err)
+	assert.Equal(t, "House", bp.GetName())
+
+	trail, err := audit.Get()
+	require.NoError(t, err)
+
+	records, err := trail.List(ctx, audit.Filter{Version: version})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "tester", records[0].Subject)
+
+	invalid := []model.BlueprintRequest{
+		{Kind: model.KindBuilding, Version: version, Definition: registry.BuildingBlueprintRequest{Name: "Hut"}},
+	}
+	assert.Error(t, SaveBlueprints(ctx, "tester", invalid))
+
+	store, err := metadata.Get()
+	require.NoError(t, err)
+
+	v, err := store.GetVersion(ctx, version)
+	require.NoError(t, err)
+	require.NoError(t, v.Promote(metadata.StateStaging, "tester"))
+	require.NoError(t, store.SaveVersion(ctx, v))
+
+	assert.ErrorIs(t, SaveBlueprints(ctx, "tester", requests), metadata.ErrFrozen)
+	assert.ErrorIs(t, CheckFrozen(ctx, version), metadata.ErrFrozen)
+}
+
+func TestCheckWritable(t *testing.T) {
+	setupStores(t)
+
+	ctx := context.Background()
+
+	store, err := metadata.Get()
+	require.NoError(t, err)
+
+	// Versions written before lifecycle records were kept have blueprints
+	// but no record. They're recorded as drafts, even the active one whose
+	// cache isn't loaded.
+	for _, version := range []string{"2.0.0", "legacy"} {
+		require.NoError(t, registry.SaveBuildingBlueprint(ctx, version, registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: "10s"}, false))
+		require.NoError(t, CheckFrozen(ctx, version))
+
+		cluster.SetActive(version)
+		require.NoError(t, CheckWritable(ctx, version))
+		cluster.SetActive("")
+
+		v, err := store.GetVersion(ctx, version)
+		require.NoError(t, err)
+		assert.Equal(t, metadata.StateDraft, v.State)
+	}
+
+	assert.ErrorIs(t, CheckWritable(ctx, "unknown"), metadata.ErrInvalidVersion)
+	assert.ErrorIs(t, CheckWritable(ctx, "3.0"), metadata.ErrInvalidVersion)
+}
+
+// failingWriter fails every replace, as if the store went away mid-save.
+type failingWriter struct {
+	blueprint.Writer
+}
+
+func (failingWriter) ReplaceBuildingBlueprint(context.Context, *proto.BuildingBlueprint) error {
+	return errors.New("connection reset")
+}
+
+func TestSaveBlueprintReplace(t *testing.T) {
+	setupStores(t)
+
+	const version = "4.0.0"
+
+	ctx := context.Background()
+
+	save := func(buildTime string) error {
+		req := model.BlueprintRequest{
+			Kind:       model.KindBuilding,
+			Version:    version,
+			Force:      true,
+			Definition: registry.BuildingBlueprintRequest{Name: "House", Slug: "house", BuildTime: buildTime},
+		}
+
+		return SaveBlueprint(ctx, &req, NewTrail("tester"))
+	}
+
+	require.NoError(t, save("10s"))
+	require.NoError(t, save("20s"))
+
+	bps, err := blueprint.ListBuildings(ctx, version)
+	require.NoError(t, err)
+	require.Len(t, bps, 1)
+	assert.Equal(t, 20*time.Second, bps[0].GetBuildTime().AsDuration())
+
+	// A replace that fails keeps the stored definition.
+	blueprint.SetWriter(failingWriter{mockwriter.New()})
+	t.Cleanup(func() { blueprint.SetWriter(mockwriter.New()) })
+
+	assert.Error(t, save("30s"))
+
+	bps, err = blueprint.ListBuildings(ctx, version)
+	require.NoError(t, err)
+	require.Len(t, bps, 1)
+	assert.Equal(t, 20*time.Second, bps[0].GetBuildTime().AsDuration())
+
+	trail, err := audit.Get()
+	require.NoError(t, err)
+
+	records, err := trail.List(ctx, audit.Filter{Version: version})
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	assert.Equal(t, saveStatusFailed, records[0].Status)
+}
@@ -0,0 +1,97 @@
+package registrar
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+)
+
+// saveStatusFailed marks the record following up a save that was recorded but
+// couldn't be written.
+const saveStatusFailed = "FAILED"
+
+// Trail records the saves of a single request. Previous definitions are
+// listed once per version and kind, before anything is saved over them.
+type Trail struct {
+	subject string
+	bases   map[string]model.BaseFunc
+}
+
+func NewTrail(subject string) *Trail {
+	return &Trail{
+		subject: subject,
+		bases:   make(map[string]model.BaseFunc),
+	}
+}
+
+// previous returns the definition req replaces, or nil for new blueprints.
+func (t *Trail) previous(ctx context.Context, req *model.BlueprintRequest) (registry.Request, error) {
+	kind, err := blueprint.Get(req.Kind)
+	if err != nil {
+		return nil, ErrInvalidKind
+	}
+
+	base, ok := t.bases[req.Version]
+	if !ok {
+		base = Base(ctx, req.Version)
+		t.bases[req.Version] = base
+	}
+
+	def, err := base(kind, req.Definition.GetSlug())
+	if errors.Is(err, model.ErrBaseNotFound) {
+		return nil, nil
+	}
+
+	return def, err
+}
+
+// save appends a record of req replacing before to the audit store.
+func (t *Trail) save(ctx context.Context, req *model.BlueprintRequest, before registry.Request) (*audit.Record, error) {
+	after, err := model.NormalizeDefinition(req.Kind, req.Definition)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous any
+	if before != nil {
+		if previous, err = model.NormalizeDefinition(req.Kind, before); err != nil {
+			return nil, err
+		}
+	}
+
+	record := audit.NewRecord(audit.ActionSave, t.subject, req.Version)
+	record.Kind = req.Kind
+	record.Slug = req.Definition.GetSlug()
+	record.Diff = audit.Diff(previous, after)
+
+	if record.Hash, err = audit.Hash(after); err != nil {
+		return nil, err
+	}
+
+	store, err := audit.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return record, store.Append(ctx, record)
+}
+
+// fail follows up a recorded save that couldn't be written.
+func (t *Trail) fail(ctx context.Context, saved *audit.Record) error {
+	store, err := audit.Get()
+	if err != nil {
+		return err
+	}
+
+	record := audit.NewRecord(audit.ActionSave, t.subject, saved.Version)
+	record.Kind = saved.Kind
+	record.Slug = saved.Slug
+	record.Hash = saved.Hash
+	record.Status = saveStatusFailed
+
+	return store.Append(ctx, record)
+}
@@ -0,0 +1,209 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/config"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// activeFile names the file holding the version the gateway served last.
+const activeFile = "active"
+
+// Snapshot is a loaded blueprint version kept on local disk, so the gateway
+// can serve it while the registry database is unreachable.
+type Snapshot struct {
+	Version    string
+	SavedAt    time.Time
+	Blueprints blueprint.Set
+}
+
+// header is the part of a snapshot file besides the blueprints, which are
+// listed under the collection of their kind.
+type header struct {
+	Version string    `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// FromCache takes a snapshot of the version loaded in the cache.
+func FromCache(ctx context.Context, version string) *Snapshot {
+	return &Snapshot{
+		Version:    version,
+		SavedAt:    time.Now().UTC(),
+		Blueprints: blueprint.FromCache(ctx),
+	}
+}
+
+// Save stores the version loaded in the cache as the active snapshot and stops
+// serving a stale one. It only records the recovery without a snapshot
+// directory.
+func Save(ctx context.Context, version string) error {
+	Recover()
+
+	dir := viper.GetString(config.FlagSnapshotDir)
+	if dir == "" {
+		return nil
+	}
+
+	if err := Write(dir, FromCache(ctx, version)); err != nil {
+		return err
+	}
+
+	return SetActive(dir, version)
+}
+
+// Write stores snap in dir. The file is replaced atomically, a crash never
+// leaves a partial snapshot behind.
+func Write(dir string, snap *Snapshot) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f := map[string]any{
+		"version":  snap.Version,
+		"saved_at": snap.SavedAt,
+	}
+
+	for name, bps := range snap.Blueprints {
+		kind, err := blueprint.Get(name)
+		if err != nil {
+			return err
+		}
+
+		items := make([]json.RawMessage, 0, len(bps))
+
+		for _, bp := range bps {
+			raw, err := protojson.Marshal(bp)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", kind.Name, bp.GetSlug(), err)
+			}
+
+			items = append(items, raw)
+		}
+
+		f[kind.Collection] = items
+	}
+
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	path, err := versionPath(dir, snap.Version)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, raw)
+}
+
+// Read loads the snapshot of version from dir. Missing snapshots return an
+// error wrapping os.ErrNotExist.
+func Read(dir, version string) (*Snapshot, error) {
+	path, err := versionPath(dir, version)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var h header
+	if err := json.Unmarshal(raw, &h); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var collections map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &collections); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	snap := &Snapshot{
+		Version:    h.Version,
+		SavedAt:    h.SavedAt,
+		Blueprints: make(blueprint.Set),
+	}
+
+	for _, kind := range blueprint.Held() {
+		collection, ok := collections[kind.Collection]
+		if !ok {
+			continue
+		}
+
+		var items []json.RawMessage
+		if err := json.Unmarshal(collection, &items); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, item := range items {
+			bp := kind.New()
+			if err := protojson.Unmarshal(item, bp); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+
+			snap.Blueprints.Add(kind.Name, bp)
+		}
+	}
+
+	snap.Blueprints.Sort()
+
+	return snap, nil
+}
+
+// SetActive marks version as the one to boot from.
+func SetActive(dir, version string) error {
+	if _, err := versionPath(dir, version); err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(dir, activeFile), []byte(version+"\n"))
+}
+
+// Active loads the snapshot of the version the gateway served last.
+func Active(dir string) (*Snapshot, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, activeFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return Read(dir, strings.TrimSpace(string(raw)))
+}
+
+func versionPath(dir, version string) (string, error) {
+	if version == "" || version == activeFile || strings.ContainsAny(version, `/\`) || strings.HasPrefix(version, ".") {
+		return "", fmt.Errorf("invalid snapshot version: %q", version)
+	}
+
+	return filepath.Join(dir, version+".json"), nil
+}
+
+func writeFile(path string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
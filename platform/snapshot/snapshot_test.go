@@ -0,0 +1,144 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func testSnapshot(version string) *Snapshot {
+	return &Snapshot{
+		Version: version,
+		SavedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Blueprints: blueprint.Set{
+			blueprint.KindBuilding: {
+				&proto.BuildingBlueprint{Name: "House", Slug: "house", BuildTime: durationpb.New(10 * time.Second)},
+			},
+			blueprint.KindResource: {
+				&proto.ResourceBlueprint{Name: "Wood", Slug: "wood"},
+			},
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, Write(dir, testSnapshot("1.0.0")))
+
+	snap, err := Read(dir, "1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.0.0", snap.Version)
+	assert.True(t, snap.SavedAt.Equal(testSnapshot("").SavedAt))
+	buildings := snap.Blueprints.Buildings()
+	require.Len(t, buildings, 1)
+	assert.Equal(t, "house", buildings[0].GetSlug())
+	assert.Equal(t, 10*time.Second, buildings[0].GetBuildTime().AsDuration())
+
+	resources := snap.Blueprints.Resources()
+	require.Len(t, resources, 1)
+	assert.Equal(t, "wood", resources[0].GetSlug())
+
+	_, err = Read(dir, "2.0.0")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = Read(dir, "../1.0.0")
+	assert.Error(t, err)
+}
+
+// pennant is a blueprint of a kind registered besides the built-in ones.
+type pennant struct {
+	*wrapperspb.StringValue
+}
+
+func (p pennant) GetSlug() string { return p.GetValue() }
+
+func TestWriteReadRegisteredKind(t *testing.T) {
+	blueprint.Register(&blueprint.Kind{
+		Name:   "pennant",
+		Decode: blueprint.Decoder[registry.ResourceBlueprintRequest](),
+		Save:   blueprint.Saver(registry.SaveResourceBlueprint),
+		Lookup: blueprint.Lookup(registry.GetResourceBlueprint),
+		Loaded: func(context.Context) []blueprint.Blueprint { return nil },
+		Read:   func(context.Context, string) ([]blueprint.Blueprint, error) { return nil, nil },
+		New:    func() blueprint.Blueprint { return pennant{&wrapperspb.StringValue{}} },
+		Request: blueprint.Requester(func(p pennant) registry.ResourceBlueprintRequest {
+			return registry.ResourceBlueprintRequest{Name: p.GetValue(), Slug: p.GetSlug()}
+		}),
+	})
+
+	dir := t.TempDir()
+
+	snap := testSnapshot("1.0.0")
+	snap.Blueprints.Add("pennant", pennant{wrapperspb.String("red")}, pennant{wrapperspb.String("blue")})
+
+	require.NoError(t, Write(dir, snap))
+
+	read, err := Read(dir, "1.0.0")
+	require.NoError(t, err)
+	require.Len(t, read.Blueprints["pennant"], 2)
+	assert.Equal(t, "blue", read.Blueprints["pennant"][0].GetSlug())
+	assert.Len(t, read.Blueprints.Buildings(), 1)
+}
+
+func TestActive(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Active(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, Write(dir, testSnapshot("1.0.0")))
+	require.NoError(t, Write(dir, testSnapshot("1.1.0")))
+	require.NoError(t, SetActive(dir, "1.0.0"))
+
+	snap, err := Active(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", snap.Version)
+
+	assert.Error(t, SetActive(dir, ""))
+}
+
+func TestRetry(t *testing.T) {
+	t.Cleanup(Recover)
+
+	cause := errors.New("connection refused")
+	Serve(testSnapshot("1.0.0"), cause)
+
+	status := Current()
+	assert.True(t, status.Stale)
+	assert.Equal(t, "1.0.0", status.Version)
+	assert.Equal(t, cause.Error(), status.LastError)
+
+	attempts := 0
+	load := func(ctx context.Context) error {
+		attempts++
+
+		if attempts < 3 {
+			return errors.New("still down")
+		}
+
+		Recover()
+
+		return nil
+	}
+
+	Retry(context.Background(), load, time.Millisecond, 2*time.Millisecond)
+
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, Status{}, Current())
+
+	// Nothing is retried once the snapshot is no longer served.
+	Retry(context.Background(), load, time.Millisecond, time.Millisecond)
+	assert.Equal(t, 3, attempts)
+}
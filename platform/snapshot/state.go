@@ -0,0 +1,170 @@
+package snapshot
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	DefaultRetryMin = time.Second
+	DefaultRetryMax = time.Minute
+)
+
+// Status describes whether the gateway serves blueprints from a stale
+// snapshot.
+type Status struct {
+	Stale      bool       `json:"stale"`
+	Version    string     `json:"version,omitempty"`
+	SnapshotAt *time.Time `json:"snapshot_at,omitempty"`
+	StaleSince *time.Time `json:"stale_since,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+}
+
+type state struct {
+	mx       *sync.Mutex
+	snapshot *Snapshot
+	since    time.Time
+	lastErr  error
+}
+
+var (
+	current = &state{mx: &sync.Mutex{}}
+
+	metersOnce = &sync.Once{}
+)
+
+// Serve makes the gateway serve snap in place of the cache, after loading the
+// cache failed with err.
+func Serve(snap *Snapshot, err error) {
+	metersOnce.Do(registerMeters)
+
+	current.mx.Lock()
+	defer current.mx.Unlock()
+
+	current.snapshot = snap
+	current.since = time.Now().UTC()
+	current.lastErr = err
+}
+
+// Recover stops serving a stale snapshot.
+func Recover() {
+	metersOnce.Do(registerMeters)
+
+	current.mx.Lock()
+	defer current.mx.Unlock()
+
+	current.snapshot = nil
+	current.since = time.Time{}
+	current.lastErr = nil
+}
+
+// Stale returns the snapshot being served while the cache can't be loaded.
+func Stale() (*Snapshot, bool) {
+	current.mx.Lock()
+	defer current.mx.Unlock()
+
+	return current.snapshot, current.snapshot != nil
+}
+
+func Current() Status {
+	current.mx.Lock()
+	defer current.mx.Unlock()
+
+	if current.snapshot == nil {
+		return Status{}
+	}
+
+	savedAt := current.snapshot.SavedAt
+	since := current.since
+
+	status := Status{
+		Stale:      true,
+		Version:    current.snapshot.Version,
+		SnapshotAt: &savedAt,
+		StaleSince: &since,
+	}
+
+	if current.lastErr != nil {
+		status.LastError = current.lastErr.Error()
+	}
+
+	return status
+}
+
+func fail(err error) {
+	current.mx.Lock()
+	defer current.mx.Unlock()
+
+	current.lastErr = err
+}
+
+// Retry calls load until it succeeds, the stale snapshot is no longer served
+// or ctx is done. The wait between attempts doubles up to maxDelay.
+func Retry(ctx context.Context, load func(ctx context.Context) error, minDelay, maxDelay time.Duration) {
+	delay := minDelay
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if _, ok := Stale(); !ok {
+			return
+		}
+
+		err := load(ctx)
+		if err == nil {
+			slog.Info("registry is reachable again, stopped serving stale snapshot")
+			return
+		}
+
+		fail(err)
+
+		delay = min(delay*2, maxDelay)
+		slog.Warn("failed to load blueprints from registry", "error", err, "retry_in", delay)
+
+		timer.Reset(delay)
+	}
+}
+
+func registerMeters() {
+	meter := otel.Meter("gateway")
+
+	// Serving works without metrics, failing gauges are only logged.
+	if _, err := meter.Int64ObservableGauge("blueprint.stale",
+		metric.WithDescription("Whether blueprints are served from a stale snapshot"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			if _, ok := Stale(); ok {
+				o.Observe(1)
+			} else {
+				o.Observe(0)
+			}
+
+			return nil
+		})); err != nil {
+		slog.Error("failed to create blueprint.stale gauge", "error", err)
+	}
+
+	if _, err := meter.Float64ObservableGauge("blueprint.snapshot.age",
+		metric.WithDescription("Age of the stale snapshot being served"),
+		metric.WithUnit("s"),
+		metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+			if snap, ok := Stale(); ok {
+				o.Observe(time.Since(snap.SavedAt).Seconds())
+			}
+
+			return nil
+		})); err != nil {
+		slog.Error("failed to create blueprint.snapshot.age gauge", "error", err)
+	}
+}
@@ -0,0 +1,314 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/daemon/model"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/registrar"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	DefaultVersion  = "0.0.0-dev"
+	DefaultDebounce = 250 * time.Millisecond
+
+	// Subject is who the audit trail records the watcher's saves as made by.
+	Subject = "watcher"
+)
+
+// ReloadFunc makes the gateway serve version from the cache.
+type ReloadFunc func(ctx context.Context, version string) error
+
+// Watcher keeps a version of the registry in sync with a directory of
+// blueprint files, for local content development.
+type Watcher struct {
+	dir      string
+	version  string
+	reload   ReloadFunc
+	debounce time.Duration
+	logger   *slog.Logger
+
+	mx     *sync.Mutex
+	synced *model.BlueprintBatchRequest
+}
+
+type Option func(*Watcher)
+
+func WithVersion(version string) Option {
+	return func(w *Watcher) {
+		w.version = version
+	}
+}
+
+func WithDebounce(debounce time.Duration) Option {
+	return func(w *Watcher) {
+		w.debounce = debounce
+	}
+}
+
+func New(dir string, reload ReloadFunc, opts ...Option) *Watcher {
+	w := &Watcher{
+		dir:      dir,
+		version:  DefaultVersion,
+		reload:   reload,
+		debounce: DefaultDebounce,
+		logger:   slog.Default().With("context", "Watcher", "dir", dir),
+		mx:       &sync.Mutex{},
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+func (w *Watcher) Version() string {
+	return w.version
+}
+
+// Run syncs the directory once and then on every change until ctx is done.
+func (w *Watcher) Run(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsw.Close()
+
+	if err := watchTree(fsw, w.dir); err != nil {
+		return err
+	}
+
+	if err := w.Sync(ctx); err != nil {
+		w.logger.Error("failed to sync blueprints", "error", err)
+	}
+
+	var (
+		timer   = time.NewTimer(w.debounce)
+		pending = false
+	)
+
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+
+			w.logger.Warn("file watcher error", "error", err)
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchTree(fsw, event.Name); err != nil {
+						w.logger.Warn("failed to watch directory", "error", err, "path", event.Name)
+					}
+				}
+			}
+
+			if !relevant(event) {
+				continue
+			}
+
+			// Editors write files in several steps, wait for them to settle.
+			if !pending {
+				pending = true
+				timer.Reset(w.debounce)
+			}
+		case <-timer.C:
+			pending = false
+
+			if err := w.Sync(ctx); err != nil {
+				w.logger.Error("failed to sync blueprints", "error", err)
+			}
+		}
+	}
+}
+
+// Sync upserts the blueprints that changed since the last sync, deletes the
+// ones whose files are gone and reloads the cache. Nothing is written while
+// any file is invalid, so a half edited file doesn't remove its blueprints.
+// Saves go through the same checks and audit trail as uploads.
+func (w *Watcher) Sync(ctx context.Context) error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	entries, err := model.ReadDir(w.dir)
+	if err == nil {
+		err = model.Lint(entries)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid blueprints:\n%w", err)
+	}
+
+	local, err := model.BatchFromEntries(w.version, entries)
+	if err != nil {
+		return err
+	}
+
+	// The directory holds the whole version, templates extend files of it.
+	if err := local.Resolve(nil); err != nil {
+		return fmt.Errorf("invalid blueprints:\n%w", err)
+	}
+
+	local.Force = true
+
+	// The first sync always reloads, the cache may hold another version.
+	first := w.synced == nil
+	if first {
+		w.synced = registered(ctx, w.version)
+	}
+
+	changes, err := model.Diff(local, w.synced)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 && !first {
+		return nil
+	}
+
+	changed := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		if change.Op != model.ChangeRemoved {
+			changed[change.Kind+"/"+change.Slug] = true
+		}
+	}
+
+	requests := make([]model.BlueprintRequest, 0, len(changed))
+	for _, req := range local.Requests() {
+		if changed[req.Kind+"/"+req.Slug()] {
+			requests = append(requests, req)
+		}
+	}
+
+	if err := registrar.SaveBlueprints(ctx, Subject, requests); err != nil {
+		return err
+	}
+
+	// Blueprints are only deleted once everything else is saved, so a failed
+	// sync never leaves the version with fewer blueprints than before.
+	if err := w.delete(ctx, changes); err != nil {
+		return err
+	}
+
+	w.synced = local
+
+	if err := w.reload(ctx, w.version); err != nil {
+		return fmt.Errorf("reload: %w", err)
+	}
+
+	w.logger.Info("synced blueprints", "version", w.version, "changes", len(changes))
+
+	return nil
+}
+
+func (w *Watcher) delete(ctx context.Context, changes []model.Change) error {
+	store, err := metadata.Get()
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change.Op != model.ChangeRemoved {
+			continue
+		}
+
+		kind, err := blueprint.Get(change.Kind)
+		if err != nil {
+			return err
+		}
+
+		if kind.Delete == nil {
+			return fmt.Errorf("%s/%s: %s blueprints can't be deleted", change.Kind, change.Slug, kind.Name)
+		}
+
+		err = kind.Delete(ctx, w.version, change.Slug)
+		if err != nil && !errors.Is(err, blueprint.ErrNotFound) {
+			return fmt.Errorf("%s/%s: %w", change.Kind, change.Slug, err)
+		}
+
+		if err := store.DeleteBlueprint(ctx, w.version, change.Kind, change.Slug); err != nil {
+			return fmt.Errorf("%s/%s: %w", change.Kind, change.Slug, err)
+		}
+	}
+
+	return nil
+}
+
+// registered reads what the registry already holds for version, so files
+// deleted while the gateway was down are removed as well.
+func registered(ctx context.Context, version string) *model.BlueprintBatchRequest {
+	batch := &model.BlueprintBatchRequest{
+		Version:    version,
+		Blueprints: make(map[string][]registry.Request),
+	}
+
+	for _, kind := range blueprint.Kinds() {
+		if kind.List == nil {
+			continue
+		}
+
+		defs, err := kind.List(ctx, version)
+		if err != nil {
+			slog.Debug("failed to list blueprints", "error", err, "kind", kind.Name, "version", version)
+			continue
+		}
+
+		batch.Blueprints[kind.Name] = defs
+	}
+
+	return batch
+}
+
+func relevant(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	switch strings.ToLower(filepath.Ext(event.Name)) {
+	case ".yaml", ".yml", ".json", "":
+		return true
+	default:
+		return false
+	}
+}
+
+func watchTree(fsw *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		return fsw.Add(path)
+	})
+}
@@ -0,0 +1,177 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/audit"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/blueprint/mockwriter"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/cluster"
+	"github.com/GnarloqGames/genesis-avalon-gateway/platform/metadata"
+	"github.com/GnarloqGames/genesis-avalon-kit/database"
+	"github.com/GnarloqGames/genesis-avalon-kit/database/mock"
+	"github.com/GnarloqGames/genesis-avalon-kit/proto"
+	"github.com/GnarloqGames/genesis-avalon-kit/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const woodFile = "kind: resource\nbody:\n  name: Wood\n  slug: wood\n"
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// setupStores selects the mock database, with a writer to delete and replace
+// its blueprints, and fresh memory stores for the metadata and the audit
+// trail, so nothing written by earlier tests is left.
+func setupStores(t *testing.T) {
+	t.Helper()
+
+	database.SetKind(database.DriverMock)
+	metadata.SetKind(database.DriverMock)
+	audit.SetKind(database.DriverMock)
+	blueprint.SetWriter(mockwriter.New())
+
+	db, err := mock.Get()
+	require.NoError(t, err)
+
+	db.Buildings = make([]*proto.Building, 0)
+	db.BuildingBlueprints = make([]*proto.BuildingBlueprint, 0)
+	db.ResourceBlueprints = make([]*proto.ResourceBlueprint, 0)
+}
+
+func buildTime(t *testing.T, version string) time.Duration {
+	t.Helper()
+
+	bp, err := registry.GetBuildingBlueprint(context.Background(), version, "house")
+	require.NoError(t, err)
+
+	return bp.GetBuildTime().AsDuration()
+}
+
+func TestSync(t *testing.T) {
+	setupStores(t)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "building", "house.yaml"), "kind: building\nbody:\n  name: House\n  slug: house\n  build_time: 10s\n")
+	writeFile(t, filepath.Join(dir, "resource", "wood.yaml"), woodFile)
+
+	var reloads atomic.Int32
+
+	w := New(dir, func(ctx context.Context, version string) error {
+		reloads.Add(1)
+		return nil
+	}, WithVersion("0.0.0-sync"))
+
+	require.NoError(t, w.Sync(context.Background()))
+	assert.Equal(t, int32(1), reloads.Load())
+	assert.Equal(t, 10*time.Second, buildTime(t, "0.0.0-sync"))
+
+	require.NoError(t, w.Sync(context.Background()))
+	assert.Equal(t, int32(1), reloads.Load(), "unchanged files don't reload")
+
+	store, err := metadata.Get()
+	require.NoError(t, err)
+
+	annotation := metadata.NewBlueprint("0.0.0-sync", "building", "house")
+	annotation.Deprecate("196176fd-6e54-49c2-9e49-eb81406c68d5")
+	require.NoError(t, store.SaveBlueprint(context.Background(), annotation))
+
+	writeFile(t, filepath.Join(dir, "building", "house.yaml"), "kind: building\nbody:\n  name: House\n  slug: house\n  build_time: 20s\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "resource", "wood.yaml")))
+
+	require.NoError(t, w.Sync(context.Background()))
+	assert.Equal(t, int32(2), reloads.Load())
+	assert.Equal(t, 20*time.Second, buildTime(t, "0.0.0-sync"))
+
+	_, err = registry.GetResourceBlueprint(context.Background(), "0.0.0-sync", "wood")
+	assert.Error(t, err)
+
+	annotation, err = store.GetBlueprint(context.Background(), "0.0.0-sync", "building", "house")
+	require.NoError(t, err)
+	assert.True(t, annotation.Deprecated, "changed blueprints keep their annotations")
+
+	trail, err := audit.Get()
+	require.NoError(t, err)
+
+	records, err := trail.List(context.Background(), audit.Filter{Version: "0.0.0-sync", Slug: "house"})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, Subject, records[0].Subject)
+
+	writeFile(t, filepath.Join(dir, "building", "house.yaml"), "kind: building\nbody:\n  name: House\n")
+	assert.Error(t, w.Sync(context.Background()))
+	assert.Equal(t, 20*time.Second, buildTime(t, "0.0.0-sync"), "invalid files leave the registry alone")
+}
+
+// The gateway starts the watcher before the cache was ever loaded.
+func TestSyncUnloaded(t *testing.T) {
+	setupStores(t)
+
+	cluster.SetActive(DefaultVersion)
+	t.Cleanup(func() {
+		cluster.SetActive("")
+	})
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "resource", "wood.yaml"), woodFile)
+
+	w := New(dir, func(ctx context.Context, version string) error { return nil })
+
+	require.NoError(t, w.Sync(context.Background()))
+
+	bp, err := registry.GetResourceBlueprint(context.Background(), DefaultVersion, "wood")
+	require.NoError(t, err)
+	assert.Equal(t, "Wood", bp.GetName())
+}
+
+func TestRun(t *testing.T) {
+	setupStores(t)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "house.yaml"), "kind: building\nbody:\n  name: House\n  slug: house\n  build_time: 10s\n")
+
+	reloaded := make(chan string, 8)
+
+	w := New(dir, func(ctx context.Context, version string) error {
+		reloaded <- version
+		return nil
+	}, WithVersion("0.0.0-run"), WithDebounce(10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	select {
+	case version := <-reloaded:
+		assert.Equal(t, "0.0.0-run", version)
+	case <-time.After(5 * time.Second):
+		t.Fatal("initial sync didn't reload")
+	}
+
+	writeFile(t, filepath.Join(dir, "nested", "wood.yaml"), woodFile)
+
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change didn't reload")
+	}
+
+	bp, err := registry.GetResourceBlueprint(context.Background(), "0.0.0-run", "wood")
+	require.NoError(t, err)
+	assert.Equal(t, "Wood", bp.GetName())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+
+	// DefaultLogSize is the number of deliveries kept in the log.
+	DefaultLogSize = 1000
+)
+
+type Delivery struct {
+	ID         string    `json:"id"`
+	Event      Event     `json:"event"`
+	URL        string    `json:"url"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// DeliveryLog keeps the latest deliveries in memory, the oldest are dropped
+// once it is full.
+type DeliveryLog struct {
+	mx         *sync.RWMutex
+	size       int
+	deliveries []*Delivery
+	byID       map[string]*Delivery
+}
+
+func NewDeliveryLog(size int) *DeliveryLog {
+	return &DeliveryLog{
+		mx:         &sync.RWMutex{},
+		size:       size,
+		deliveries: make([]*Delivery, 0),
+		byID:       make(map[string]*Delivery),
+	}
+}
+
+func (l *DeliveryLog) add(event Event, url string) string {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	now := time.Now().UTC()
+	d := &Delivery{
+		ID:        uuid.NewString(),
+		Event:     event,
+		URL:       url,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if len(l.deliveries) >= l.size {
+		delete(l.byID, l.deliveries[0].ID)
+		l.deliveries = l.deliveries[1:]
+	}
+
+	l.deliveries = append(l.deliveries, d)
+	l.byID[d.ID] = d
+
+	return d.ID
+}
+
+func (l *DeliveryLog) update(id string, attempts int, status string, code int, err error) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	d, ok := l.byID[id]
+	if !ok {
+		return
+	}
+
+	d.Attempts = attempts
+	d.Status = status
+	d.StatusCode = code
+	d.UpdatedAt = time.Now().UTC()
+	d.Error = ""
+
+	if err != nil {
+		d.Error = err.Error()
+	}
+}
+
+// List returns the deliveries with the given status, or all of them for an
+// empty status, newest first.
+func (l *DeliveryLog) List(status string) []Delivery {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	deliveries := make([]Delivery, 0, len(l.deliveries))
+
+	for i := len(l.deliveries) - 1; i >= 0; i-- {
+		if status == "" || l.deliveries[i].Status == status {
+			deliveries = append(deliveries, *l.deliveries[i])
+		}
+	}
+
+	return deliveries
+}
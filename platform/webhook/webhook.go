@@ -0,0 +1,264 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventVersionWritten   = "version.written"
+	EventVersionActivated = "version.activated"
+
+	HeaderEvent     = "X-Avalon-Event"
+	HeaderDelivery  = "X-Avalon-Delivery"
+	HeaderSignature = "X-Avalon-Signature"
+
+	DefaultAttempts = 5
+	DefaultRetryMin = time.Second
+	DefaultRetryMax = time.Minute
+	DefaultTimeout  = 10 * time.Second
+
+	// DrainTimeout bounds how long shutdown waits for deliveries in flight.
+	DrainTimeout = 30 * time.Second
+)
+
+// Event is the JSON body posted to subscribers.
+type Event struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Version string    `json:"version"`
+	Subject string    `json:"subject,omitempty"`
+	Count   int       `json:"count,omitempty"`
+	Status  string    `json:"status,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+func NewEvent(eventType, version string) Event {
+	return Event{
+		ID:      uuid.NewString(),
+		Type:    eventType,
+		Version: version,
+		Time:    time.Now().UTC(),
+	}
+}
+
+// Subscription is a URL events are posted to. Without events it receives every
+// event.
+type Subscription struct {
+	URL    string   `json:"url" mapstructure:"url"`
+	Secret string   `json:"-" mapstructure:"secret"`
+	Events []string `json:"events,omitempty" mapstructure:"events"`
+}
+
+// Validate checks that the subscription has an absolute HTTP(S) URL.
+func (s Subscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url %q: %w", s.URL, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %q: not an absolute http(s) url", s.URL)
+	}
+
+	return nil
+}
+
+func (s Subscription) wants(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign returns the signature of body sent in the signature header: the
+// hex-encoded HMAC-SHA256 of the body keyed with the subscription's secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature made with Sign, for receivers written in Go.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Dispatcher posts events to the subscriptions that want them. Deliveries
+// run in the background and are retried with exponential backoff.
+type Dispatcher struct {
+	subscriptions []Subscription
+	client        *http.Client
+	log           *DeliveryLog
+	wg            *sync.WaitGroup
+
+	Attempts int
+	RetryMin time.Duration
+	RetryMax time.Duration
+}
+
+func NewDispatcher(subscriptions []Subscription) *Dispatcher {
+	return &Dispatcher{
+		subscriptions: subscriptions,
+		client:        &http.Client{Timeout: DefaultTimeout},
+		log:           NewDeliveryLog(DefaultLogSize),
+		wg:            &sync.WaitGroup{},
+		Attempts:      DefaultAttempts,
+		RetryMin:      DefaultRetryMin,
+		RetryMax:      DefaultRetryMax,
+	}
+}
+
+func (d *Dispatcher) Log() *DeliveryLog {
+	return d.log
+}
+
+// Publish starts delivering event to every subscription that wants it.
+func (d *Dispatcher) Publish(event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("failed to encode webhook event", "error", err, "event", event.Type)
+		return
+	}
+
+	for _, sub := range d.subscriptions {
+		if !sub.wants(event.Type) {
+			continue
+		}
+
+		delivery := d.log.add(event, sub.URL)
+
+		d.wg.Add(1)
+
+		go func(sub Subscription, delivery string) {
+			defer d.wg.Done()
+			d.deliver(sub, event, delivery, body)
+		}(sub, delivery)
+	}
+}
+
+// Wait blocks until every delivery started so far succeeded or gave up.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Drain waits like Wait, but gives up once ctx is done. Deliveries still
+// running then are abandoned.
+func (d *Dispatcher) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) deliver(sub Subscription, event Event, delivery string, body []byte) {
+	delay := d.RetryMin
+
+	for attempt := 1; ; attempt++ {
+		code, err := d.post(sub, event, delivery, body)
+		if err == nil {
+			d.log.update(delivery, attempt, StatusDelivered, code, nil)
+			return
+		}
+
+		if attempt >= d.Attempts {
+			slog.Warn("webhook delivery failed", "error", err, "url", sub.URL, "event", event.Type, "delivery", delivery, "attempts", attempt)
+			d.log.update(delivery, attempt, StatusFailed, code, err)
+
+			return
+		}
+
+		d.log.update(delivery, attempt, StatusPending, code, err)
+
+		time.Sleep(delay)
+		delay = min(delay*2, d.RetryMax)
+	}
+}
+
+func (d *Dispatcher) post(sub Subscription, event Event, delivery string, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, delivery)
+
+	if sub.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(sub.Secret, body))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+var (
+	mx         = &sync.Mutex{}
+	dispatcher *Dispatcher
+)
+
+// SetDispatcher makes d the dispatcher of Publish. A nil d turns webhooks off.
+func SetDispatcher(d *Dispatcher) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	dispatcher = d
+}
+
+// Default returns the dispatcher set with SetDispatcher, or nil.
+func Default() *Dispatcher {
+	mx.Lock()
+	defer mx.Unlock()
+
+	return dispatcher
+}
+
+// Publish delivers event with the default dispatcher, if there is one.
+func Publish(event Event) {
+	if d := Default(); d != nil {
+		d.Publish(event)
+	}
+}
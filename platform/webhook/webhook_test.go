@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receiver struct {
+	mx       *sync.Mutex
+	failures int
+	events   []Event
+	valid    []bool
+}
+
+func newReceiver(t *testing.T, secret string, failures int) (*receiver, *httptest.Server) {
+	rec := &receiver{mx: &sync.Mutex{}, failures: failures}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.mx.Lock()
+		defer rec.mx.Unlock()
+
+		if rec.failures > 0 {
+			rec.failures--
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var event Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, event.Type, r.Header.Get(HeaderEvent))
+		assert.NotEmpty(t, r.Header.Get(HeaderDelivery))
+
+		rec.events = append(rec.events, event)
+		rec.valid = append(rec.valid, Verify(secret, body, r.Header.Get(HeaderSignature)))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return rec, server
+}
+
+func newTestDispatcher(subscriptions ...Subscription) *Dispatcher {
+	d := NewDispatcher(subscriptions)
+	d.Attempts = 3
+	d.RetryMin = time.Millisecond
+	d.RetryMax = 2 * time.Millisecond
+
+	return d
+}
+
+func TestDispatcher(t *testing.T) {
+	flaky, flakyServer := newReceiver(t, "flaky-secret", 2)
+	down, downServer := newReceiver(t, "", 10)
+	filtered, filteredServer := newReceiver(t, "", 0)
+
+	d := newTestDispatcher(
+		Subscription{URL: flakyServer.URL, Secret: "flaky-secret"},
+		Subscription{URL: downServer.URL},
+		Subscription{URL: filteredServer.URL, Events: []string{EventVersionActivated}},
+	)
+
+	event := NewEvent(EventVersionWritten, "1.0.0")
+	event.Count = 3
+
+	d.Publish(event)
+	d.Wait()
+
+	require.Len(t, flaky.events, 1)
+	assert.Equal(t, event.ID, flaky.events[0].ID)
+	assert.Equal(t, 3, flaky.events[0].Count)
+	assert.True(t, flaky.valid[0])
+
+	assert.Empty(t, down.events)
+	assert.Empty(t, filtered.events)
+
+	deliveries := d.Log().List("")
+	require.Len(t, deliveries, 2)
+
+	byURL := make(map[string]Delivery)
+	for _, delivery := range deliveries {
+		byURL[delivery.URL] = delivery
+	}
+
+	assert.Equal(t, StatusDelivered, byURL[flakyServer.URL].Status)
+	assert.Equal(t, 3, byURL[flakyServer.URL].Attempts)
+	assert.Equal(t, http.StatusOK, byURL[flakyServer.URL].StatusCode)
+	assert.Empty(t, byURL[flakyServer.URL].Error)
+
+	assert.Equal(t, StatusFailed, byURL[downServer.URL].Status)
+	assert.Equal(t, 3, byURL[downServer.URL].Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, byURL[downServer.URL].StatusCode)
+	assert.NotEmpty(t, byURL[downServer.URL].Error)
+
+	failed := d.Log().List(StatusFailed)
+	require.Len(t, failed, 1)
+	assert.Equal(t, downServer.URL, failed[0].URL)
+
+	d.Publish(NewEvent(EventVersionActivated, "1.0.0"))
+	d.Wait()
+
+	require.Len(t, filtered.events, 1)
+	assert.Equal(t, EventVersionActivated, d.Log().List("")[0].Event.Type)
+}
+
+func TestDrain(t *testing.T) {
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+
+	d := newTestDispatcher(Subscription{URL: server.URL})
+	d.Publish(NewEvent(EventVersionWritten, "1.0.0"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, d.Drain(ctx), context.DeadlineExceeded)
+
+	close(release)
+
+	assert.NoError(t, d.Drain(context.Background()))
+	assert.Equal(t, StatusDelivered, d.Log().List("")[0].Status)
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"type":"version.written"}`)
+	signature := Sign("secret", body)
+
+	assert.True(t, Verify("secret", body, signature))
+	assert.False(t, Verify("other", body, signature))
+	assert.False(t, Verify("secret", []byte(`{}`), signature))
+}
+
+func TestDeliveryLog(t *testing.T) {
+	log := NewDeliveryLog(2)
+
+	first := log.add(NewEvent(EventVersionWritten, "1.0.0"), "http://a")
+	log.add(NewEvent(EventVersionWritten, "2.0.0"), "http://a")
+	log.add(NewEvent(EventVersionWritten, "3.0.0"), "http://a")
+
+	deliveries := log.List("")
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, "3.0.0", deliveries[0].Event.Version)
+	assert.Equal(t, "2.0.0", deliveries[1].Event.Version)
+
+	// Updates of dropped deliveries are ignored.
+	log.update(first, 1, StatusDelivered, http.StatusOK, nil)
+	assert.Empty(t, log.List(StatusDelivered))
+}
+
+func TestSubscriptionValidate(t *testing.T) {
+	assert.NoError(t, Subscription{URL: "https://example.com/hook"}.Validate())
+	assert.Error(t, Subscription{URL: "example.com/hook"}.Validate())
+	assert.Error(t, Subscription{URL: "ftp://example.com"}.Validate())
+}